@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"internal/auth"
+	"internal/database"
+	"internal/media"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxChirpAttachments = 4
+	// Uploads must be posted within unattachedAttachmentTTL; after that the
+	// sweeper deletes them.
+	unattachedAttachmentTTL = 24 * time.Hour
+	attachmentSweepInterval = time.Hour
+)
+
+type attachmentResponse struct {
+	ID          uuid.UUID         `json:"id"`
+	URL         string            `json:"url"`
+	ContentType string            `json:"content_type"`
+	Size        int32             `json:"size"`
+	Width       int32             `json:"width"`
+	Height      int32             `json:"height"`
+	Thumbnail   thumbnailResponse `json:"thumbnail"`
+}
+
+type thumbnailResponse struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int32  `json:"width"`
+	Height      int32  `json:"height"`
+}
+
+func attachmentKey(id uuid.UUID) string {
+	return "attachments/" + id.String()
+}
+
+func thumbnailKey(id uuid.UUID) string {
+	return "thumbnails/" + id.String()
+}
+
+func (cfg *apiConfig) newAttachmentResponse(attachment database.Attachment) attachmentResponse {
+	url := fmt.Sprintf("%s/api/attachments/%s", cfg.publicURL, attachment.ID)
+	return attachmentResponse{
+		ID:          attachment.ID,
+		URL:         url,
+		ContentType: attachment.ContentType,
+		Size:        attachment.SizeBytes,
+		Width:       attachment.Width,
+		Height:      attachment.Height,
+		Thumbnail: thumbnailResponse{
+			URL:         url + "/thumbnail",
+			ContentType: attachment.ThumbnailContentType,
+			Width:       attachment.ThumbnailWidth,
+			Height:      attachment.ThumbnailHeight,
+		},
+	}
+}
+
+// handlerUploadAttachment accepts an image as the "file" field of a
+// multipart form. The image is re-encoded without its metadata and stored
+// with a thumbnail; the returned ID can then be posted with a chirp.
+func (cfg *apiConfig) handlerUploadAttachment(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	// Leave room for the multipart headers around the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, int64(cfg.mediaLimits.MaxBytes)+64<<10)
+	file, _, err := r.FormFile("file")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondWithError(w, 413, "Attachments can be at most "+formatBytes(cfg.mediaLimits.MaxBytes))
+		return
+	} else if err != nil {
+		respondWithError(w, 400, "Send the image as the file field of a multipart form")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, int64(cfg.mediaLimits.MaxBytes)+1))
+	if err != nil {
+		log.Printf("Error reading upload: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	processed, err := media.Process(data, cfg.mediaLimits)
+	switch {
+	case errors.Is(err, media.ErrTooLarge):
+		respondWithError(w, 413, "Attachments can be at most "+formatBytes(cfg.mediaLimits.MaxBytes))
+		return
+	case errors.Is(err, media.ErrUnsupportedType):
+		respondWithError(w, 415, "Attachments must be JPEG, PNG, GIF or WebP images")
+		return
+	case errors.Is(err, media.ErrInvalidImage):
+		respondWithError(w, 400, "The image could not be read")
+		return
+	case err != nil:
+		log.Printf("Error processing upload: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	id := uuid.New()
+	original, thumbnail := processed.Original, processed.Thumbnail
+	err = cfg.blobs.Put(r.Context(), attachmentKey(id), original.ContentType, original.Data)
+	if err == nil {
+		err = cfg.blobs.Put(r.Context(), thumbnailKey(id), thumbnail.ContentType, thumbnail.Data)
+	}
+	if err != nil {
+		log.Printf("Error storing attachment: %s", err)
+		cfg.deleteAttachmentBlobs(r.Context(), id)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	attachment, err := cfg.db.CreateAttachment(r.Context(), database.CreateAttachmentParams{
+		ID:                   id,
+		UserID:               userId,
+		ContentType:          original.ContentType,
+		SizeBytes:            int32(len(original.Data)),
+		Width:                int32(original.Width),
+		Height:               int32(original.Height),
+		ThumbnailContentType: thumbnail.ContentType,
+		ThumbnailWidth:       int32(thumbnail.Width),
+		ThumbnailHeight:      int32(thumbnail.Height),
+	})
+	if err != nil {
+		log.Printf("Error creating attachment: %s", err)
+		cfg.deleteAttachmentBlobs(r.Context(), id)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 201, cfg.newAttachmentResponse(attachment))
+}
+
+func (cfg *apiConfig) handlerGetAttachment(w http.ResponseWriter, r *http.Request) {
+	cfg.serveAttachment(w, r, false)
+}
+
+func (cfg *apiConfig) handlerGetAttachmentThumbnail(w http.ResponseWriter, r *http.Request) {
+	cfg.serveAttachment(w, r, true)
+}
+
+// serveAttachment streams an attachment or its thumbnail from the blob
+// store. Attachments never change once uploaded, so they can be cached
+// indefinitely.
+func (cfg *apiConfig) serveAttachment(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	attachmentId, err := uuid.Parse(r.PathValue("attachmentID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested attachment was not found")
+		return
+	}
+	attachment, err := cfg.db.GetAttachment(r.Context(), attachmentId)
+	if err == sql.ErrNoRows {
+		respondWithError(w, 404, "The requested attachment was not found")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving attachment: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	key, contentType := attachmentKey(attachment.ID), attachment.ContentType
+	if thumbnail {
+		key, contentType = thumbnailKey(attachment.ID), attachment.ThumbnailContentType
+	}
+	blob, err := cfg.blobs.Get(r.Context(), key)
+	if errors.Is(err, media.ErrNotFound) {
+		respondWithError(w, 404, "The requested attachment was not found")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving attachment blob: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.WriteHeader(200)
+	_, err = io.Copy(w, blob)
+	if err != nil {
+		log.Printf("Error sending attachment: %s", err)
+	}
+}
+
+// attachToChirp claims the uploads in ids for a new chirp, in order. It
+// reports false if any of them is not a recent, unattached upload by the
+// chirp's author.
+func attachToChirp(ctx context.Context, q *database.Queries, chirp database.Chirp, ids []uuid.UUID) (bool, error) {
+	attached, err := q.AttachToChirp(ctx, database.AttachToChirpParams{
+		ChirpID:    chirp.ID,
+		Ids:        ids,
+		UserID:     chirp.UserID,
+		TtlSeconds: unattachedAttachmentTTL.Seconds(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return attached == int64(len(ids)), nil
+}
+
+// validateAttachmentIDs checks the attachment list of a chirp post before
+// anything is stored.
+func validateAttachmentIDs(ids []uuid.UUID) error {
+	if len(ids) > maxChirpAttachments {
+		return fmt.Errorf("a chirp can have at most %d attachments", maxChirpAttachments)
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return errors.New("each attachment can only be added once")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// chirpAttachments looks up the attachments of several chirps with one
+// query. Errors are logged and leave the attachments off rather than failing
+// the request they are shown on.
+func (cfg *apiConfig) chirpAttachments(ctx context.Context, chirpIDs ...uuid.UUID) map[uuid.UUID][]attachmentResponse {
+	if len(chirpIDs) == 0 {
+		return nil
+	}
+	attachments, err := cfg.db.GetChirpsAttachments(ctx, chirpIDs)
+	if err != nil {
+		log.Printf("Error retrieving chirp attachments: %s", err)
+		return nil
+	}
+	byChirp := make(map[uuid.UUID][]attachmentResponse)
+	for _, attachment := range attachments {
+		chirpId := attachment.ChirpID.UUID
+		byChirp[chirpId] = append(byChirp[chirpId], cfg.newAttachmentResponse(attachment))
+	}
+	return byChirp
+}
+
+// loadAttachments fills in the attachments of a list of chirps.
+func (cfg *apiConfig) loadAttachments(ctx context.Context, chirps []chirpResponse) {
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		ids = append(ids, chirp.ID)
+	}
+	byChirp := cfg.chirpAttachments(ctx, ids...)
+	for i := range chirps {
+		chirps[i].Attachments = byChirp[chirps[i].ID]
+	}
+}
+
+// newChirpResponseWithAttachments is newChirpResponse for a chirp shown on
+// its own.
+func (cfg *apiConfig) newChirpResponseWithAttachments(ctx context.Context, chirp database.Chirp) chirpResponse {
+	response := newChirpResponse(chirp)
+	response.Attachments = cfg.chirpAttachments(ctx, chirp.ID)[chirp.ID]
+	return response
+}
+
+func (cfg *apiConfig) deleteAttachmentBlobs(ctx context.Context, id uuid.UUID) {
+	for _, key := range []string{attachmentKey(id), thumbnailKey(id)} {
+		err := cfg.blobs.Delete(ctx, key)
+		if err != nil {
+			log.Printf("Error deleting attachment blob %s: %s", key, err)
+		}
+	}
+}
+
+// sweepAttachments periodically deletes attachments that were never posted,
+// along with their blobs. It runs until ctx is done.
+func (cfg *apiConfig) sweepAttachments(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		stale, err := cfg.db.DeleteStaleAttachments(ctx, unattachedAttachmentTTL.Seconds())
+		if err != nil {
+			log.Printf("Error deleting stale attachments: %s", err)
+		}
+		for _, id := range stale {
+			cfg.deleteAttachmentBlobs(ctx, id)
+		}
+		if len(stale) > 0 {
+			log.Printf("Deleted %d stale attachments", len(stale))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func formatBytes(n int) string {
+	if n >= 1<<20 && n%(1<<20) == 0 {
+		return fmt.Sprintf("%d MiB", n>>20)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
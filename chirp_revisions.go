@@ -0,0 +1,202 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"internal/auth"
+	"internal/database"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type chirpEdit struct {
+	Body string `json:"body"`
+}
+
+type chirpRevisionResponse struct {
+	Revision  int32     `json:"revision"`
+	CreatedAt time.Time `json:"created_at"`
+	Body      string    `json:"body"`
+}
+
+// handlerEditChirp replaces the body of a chirp, keeping the previous body
+// as a revision. Only the author may edit, only if their tier allows it,
+// and only within the tier's edit window.
+func (cfg *apiConfig) handlerEditChirp(w http.ResponseWriter, r *http.Request) {
+	chirpId, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	edit := chirpEdit{}
+	err = decoder.Decode(&edit)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	author, err := cfg.db.GetUser(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	perks := cfg.tiers.forUser(author)
+	if !perks.CanEditChirps {
+		respondWithError(w, 403, "Editing chirps requires Chirpy Red")
+		return
+	}
+
+	filtered, ok := cfg.prepareChirpBody(w, edit.Body, perks)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// Locking the chirp keeps concurrent edits from both claiming the same
+	// revision number.
+	chirp, err := qtx.GetChirpForUpdate(r.Context(), chirpId)
+	if err == sql.ErrNoRows {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if chirp.UserID != userId {
+		respondWithError(w, 403, "Unauthorized")
+		return
+	}
+	editWindow := time.Duration(perks.EditWindowSeconds) * time.Second
+	if editWindow > 0 && time.Since(chirp.CreatedAt) > editWindow {
+		respondWithError(w, 403, "The edit window for this chirp has closed")
+		return
+	}
+	if filtered.Body == chirp.Body {
+		respondWithJSON(w, 200, cfg.newChirpResponseWithAttachments(r.Context(), chirp))
+		return
+	}
+
+	err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.UpdatedAt,
+		Revision:  chirp.EditCount,
+		Body:      chirp.Body,
+	})
+	if err != nil {
+		log.Printf("Error storing chirp revision: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	updatedChirp, err := qtx.UpdateChirp(r.Context(), database.UpdateChirpParams{
+		Body: filtered.Body,
+		ID:   chirp.ID,
+	})
+	if err != nil {
+		log.Printf("Error updating chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	previouslyMentioned, err := qtx.GetChirpMentions(r.Context(), chirp.ID)
+	if err != nil {
+		log.Printf("Error retrieving chirp mentions: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	mentioned, err := indexChirpEntities(r.Context(), qtx, updatedChirp)
+	if err != nil {
+		log.Printf("Error indexing chirp tags and mentions: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error updating chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	if filtered.Flagged {
+		cfg.flagChirpForReview(r.Context(), updatedChirp.ID, filtered.Matches)
+	}
+	cfg.publishChirpEvent(r.Context(), chirpUpdatedEvent, updatedChirp)
+	// Only users the edit newly mentions hear about it.
+	for _, mentionedId := range mentioned {
+		if !slices.Contains(previouslyMentioned, mentionedId) {
+			cfg.notify(r.Context(), mentionedId, updatedChirp.UserID, notificationMention,
+				uuid.NullUUID{UUID: updatedChirp.ID, Valid: true})
+		}
+	}
+
+	respondWithJSON(w, 200, cfg.newChirpResponseWithAttachments(r.Context(), updatedChirp))
+}
+
+// handlerGetChirpRevisions lists the bodies a chirp had before its current
+// one, oldest first.
+func (cfg *apiConfig) handlerGetChirpRevisions(w http.ResponseWriter, r *http.Request) {
+	chirpId, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	}
+
+	_, err = cfg.db.GetChirp(r.Context(), chirpId)
+	if err == sql.ErrNoRows {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	revisions, err := cfg.db.GetChirpRevisions(r.Context(), chirpId)
+	if err != nil {
+		log.Printf("Error retrieving chirp revisions: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	revisionResponses := make([]chirpRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionResponses = append(revisionResponses, chirpRevisionResponse{
+			Revision:  revision.Revision,
+			CreatedAt: revision.CreatedAt,
+			Body:      revision.Body,
+		})
+	}
+	respondWithJSON(w, 200, revisionResponses)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"internal/chirptext"
+	"internal/database"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type contentFilterResponse struct {
+	Words int `json:"words"`
+}
+
+type chirpReviewResponse struct {
+	ChirpID      uuid.UUID `json:"chirp_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	MatchedWords []string  `json:"matched_words"`
+	Body         string    `json:"body"`
+	UserID       uuid.UUID `json:"user_id"`
+}
+
+// loadContentFilter (re)reads the content filter from contentFilterFile, or
+// uses the default word list when none is configured. The filter in use is
+// only replaced once the new one has loaded successfully.
+func (cfg *apiConfig) loadContentFilter() (*chirptext.Filter, error) {
+	var filter *chirptext.Filter
+	var err error
+	if cfg.contentFilterFile == "" {
+		filter, err = chirptext.NewFilter(chirptext.DefaultRules)
+	} else {
+		filter, err = chirptext.LoadFilter(cfg.contentFilterFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg.contentFilter.Store(filter)
+	return filter, nil
+}
+
+func (cfg *apiConfig) handlerReloadContentFilter(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	filter, err := cfg.loadContentFilter()
+	if err != nil {
+		log.Printf("Error reloading content filter: %s", err)
+		respondWithError(w, 500, "Could not reload the content filter, the previous one is still in use")
+		return
+	}
+	log.Printf("Admin reloaded content filter with %d words", filter.Len())
+
+	respondWithJSON(w, 200, contentFilterResponse{
+		Words: filter.Len(),
+	})
+}
+
+// flagChirpForReview queues a chirp for moderators. Failing to do so is
+// logged rather than failing the post, which has already been made.
+func (cfg *apiConfig) flagChirpForReview(ctx context.Context, chirpID uuid.UUID, matches []string) {
+	err := cfg.db.CreateChirpReview(ctx, database.CreateChirpReviewParams{
+		ChirpID:      chirpID,
+		MatchedWords: matches,
+	})
+	if err != nil {
+		log.Printf("Error flagging chirp %s for review: %s", chirpID, err)
+	}
+}
+
+// handlerGetChirpReviews lists published chirps the content filter flagged,
+// oldest first.
+func (cfg *apiConfig) handlerGetChirpReviews(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	limit := int32(defaultPageLimit)
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		n, err := strconv.Atoi(limitParam)
+		if err != nil || n < 1 {
+			respondWithError(w, 400, "limit must be a positive integer")
+			return
+		}
+		limit = int32(min(n, maxPageLimit))
+	}
+
+	reviews, err := cfg.db.GetChirpReviews(r.Context(), limit)
+	if err != nil {
+		log.Printf("Error retrieving chirp reviews: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	reviewResponses := make([]chirpReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		reviewResponses = append(reviewResponses, chirpReviewResponse{
+			ChirpID:      review.ChirpID,
+			CreatedAt:    review.CreatedAt,
+			MatchedWords: review.MatchedWords,
+			Body:         review.Body,
+			UserID:       review.UserID,
+		})
+	}
+	respondWithJSON(w, 200, reviewResponses)
+}
+
+// handlerResolveChirpReview takes a chirp off the review queue once a
+// moderator has looked at it.
+func (cfg *apiConfig) handlerResolveChirpReview(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested review was not found")
+		return
+	}
+
+	deleted, err := cfg.db.DeleteChirpReview(r.Context(), chirpID)
+	if err != nil {
+		log.Printf("Error resolving chirp review: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, 404, "The requested review was not found")
+		return
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
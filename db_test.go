@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"internal/auth"
+	"internal/database"
+	"internal/stream"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// newTestConfig returns an apiConfig backed by the database at
+// CHIRPY_TEST_DB_URL, which must already have the migrations applied. Tests
+// that need a database are skipped when it is unset.
+func newTestConfig(t *testing.T) *apiConfig {
+	t.Helper()
+	dbURL := os.Getenv("CHIRPY_TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("CHIRPY_TEST_DB_URL is not set")
+	}
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &apiConfig{
+		platform: "dev",
+		db:       database.New(db),
+		dbConn:   db,
+		events:   stream.NewMemoryBroker(streamHistorySize, streamBufferSize),
+	}
+}
+
+// createTestUser creates a user with a unique email and the given password.
+func createTestUser(t *testing.T, cfg *apiConfig, password string) database.User {
+	t.Helper()
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := cfg.db.CreateUser(context.Background(), database.CreateUserParams{
+		Email:          uuid.NewString() + "@example.com",
+		HashedPassword: hash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
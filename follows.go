@@ -0,0 +1,278 @@
+package main
+
+import (
+	"database/sql"
+	"internal/auth"
+	"internal/database"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// userProfile is the public view of a user; unlike User it carries no
+// email address.
+type userProfile struct {
+	ID             uuid.UUID `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Handle         string    `json:"handle,omitempty"`
+	IsChirpyRed    bool      `json:"is_chirpy_red"`
+	FollowerCount  int64     `json:"follower_count"`
+	FollowingCount int64     `json:"following_count"`
+}
+
+type followResponse struct {
+	UserID     uuid.UUID `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type followsPage struct {
+	Users      []followResponse `json:"users"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerGetUserProfile(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userId)
+	if err == sql.ErrNoRows {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	counts, err := cfg.db.GetFollowCounts(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error counting follows: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 200, userProfile{
+		ID:             user.ID,
+		CreatedAt:      user.CreatedAt,
+		Handle:         user.Handle.String,
+		IsChirpyRed:    user.IsChirpyRed,
+		FollowerCount:  counts.FollowerCount,
+		FollowingCount: counts.FollowingCount,
+	})
+}
+
+// handlerFollowUser makes the authenticated user follow userID. Following
+// someone already followed is not an error.
+func (cfg *apiConfig) handlerFollowUser(w http.ResponseWriter, r *http.Request) {
+	followeeId, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+	if followeeId == userId {
+		respondWithError(w, 400, "You cannot follow yourself")
+		return
+	}
+
+	_, err = cfg.db.GetUser(r.Context(), followeeId)
+	if err == sql.ErrNoRows {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	followed, err := cfg.db.CreateFollow(r.Context(), database.CreateFollowParams{
+		FollowerID: userId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		log.Printf("Error creating follow: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if followed > 0 {
+		cfg.notify(r.Context(), followeeId, userId, notificationFollow, uuid.NullUUID{})
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+func (cfg *apiConfig) handlerUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	followeeId, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	err = cfg.db.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID: userId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		log.Printf("Error deleting follow: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+// handlerGetFollowers lists who follows userID, most recent first.
+func (cfg *apiConfig) handlerGetFollowers(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	followers, err := cfg.db.GetFollowers(r.Context(), database.GetFollowersParams{
+		UserID:         userId,
+		AfterCreatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving followers: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	follows := make([]followResponse, 0, len(followers))
+	for _, follower := range followers {
+		follows = append(follows, followResponse{UserID: follower.UserID, FollowedAt: follower.CreatedAt})
+	}
+	respondWithJSON(w, 200, newFollowsPage(follows, page.Limit))
+}
+
+// handlerGetFollowing lists who userID follows, most recent first.
+func (cfg *apiConfig) handlerGetFollowing(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	following, err := cfg.db.GetFollowing(r.Context(), database.GetFollowingParams{
+		UserID:         userId,
+		AfterCreatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving following: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	follows := make([]followResponse, 0, len(following))
+	for _, followee := range following {
+		follows = append(follows, followResponse{UserID: followee.UserID, FollowedAt: followee.CreatedAt})
+	}
+	respondWithJSON(w, 200, newFollowsPage(follows, page.Limit))
+}
+
+// handlerGetTimeline returns the authenticated user's home timeline: their
+// own chirps and those of everyone they follow, newest first.
+func (cfg *apiConfig) handlerGetTimeline(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	// GetTimeline takes at most a page from each author before merging, so
+	// its cost grows with the number of authors followed times the page
+	// size rather than with how much they have posted.
+	timeline, err := cfg.db.GetTimeline(r.Context(), database.GetTimelineParams{
+		UserID:         userId,
+		AfterCreatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving timeline: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	dbChirps := make([]database.Chirp, 0, len(timeline))
+	for _, chirp := range timeline {
+		dbChirps = append(dbChirps, database.Chirp(chirp))
+	}
+	response := newChirpsPage(dbChirps, page.Limit)
+	cfg.loadAttachments(r.Context(), response.Chirps)
+	respondWithJSON(w, 200, response)
+}
+
+// newFollowsPage trims the look-ahead row like newChirpsPage does.
+func newFollowsPage(follows []followResponse, limit int32) followsPage {
+	page := followsPage{Users: follows}
+	if int32(len(follows)) > limit {
+		page.Users = follows[:limit]
+		last := page.Users[len(page.Users)-1]
+		page.NextCursor = pageCursor{CreatedAt: last.FollowedAt, ID: last.UserID}.encode()
+	}
+	return page
+}
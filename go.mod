@@ -4,7 +4,11 @@ go 1.23.6
 
 require (
 	github.com/golang-jwt/jwt/v5 v5.2.1 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/crypto v0.36.0 // indirect
+	golang.org/x/image v0.25.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
 )
 
 require internal/database v0.0.0
@@ -19,3 +23,22 @@ require (
 )
 
 replace internal/auth => ./internal/auth
+
+require internal/mail v0.0.0
+
+replace internal/mail => ./internal/mail
+
+require internal/chirptext v0.0.0
+
+replace internal/chirptext => ./internal/chirptext
+
+require internal/media v0.0.0
+
+replace internal/media => ./internal/media
+
+require (
+	github.com/gorilla/websocket v1.5.3
+	internal/stream v0.0.0
+)
+
+replace internal/stream => ./internal/stream
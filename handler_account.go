@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"internal/auth"
+	"internal/database"
+	"internal/mail"
+	"log"
+	"net/http"
+	"time"
+)
+
+const mailSendTimeout = 30 * time.Second
+
+type emailVerificationRequest struct {
+	Token string `json:"token"`
+}
+
+type passwordResetRequest struct {
+	Email    string `json:"email"`
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// handlerRequestPasswordReset always answers 202 so the endpoint cannot be
+// used to find out which addresses have accounts.
+func (cfg *apiConfig) handlerRequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	resetReq := passwordResetRequest{}
+	err := decoder.Decode(&resetReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	user, err := cfg.db.GetUserFromEmail(r.Context(), resetReq.Email)
+	if err == sql.ErrNoRows {
+		respondWithJSON(w, 202, struct{}{})
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	resetToken, err := auth.MakeOneTimeToken()
+	if err != nil {
+		log.Printf("Error creating reset token: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	err = cfg.db.CreatePasswordResetToken(r.Context(), database.CreatePasswordResetTokenParams{
+		TokenHash: auth.HashOneTimeToken(resetToken, cfg.tokenPepper),
+		UserID:    user.ID,
+	})
+	if err != nil {
+		log.Printf("Error storing reset token: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	cfg.sendMail(r.Context(), mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body: fmt.Sprintf("Someone asked to reset the password for your Chirpy account.\n\n"+
+			"Use this link within the next hour to choose a new one:\n\n"+
+			"%s/app/reset-password?token=%s\n\n"+
+			"If this wasn't you, you can ignore this email.\n", cfg.publicURL, resetToken),
+	})
+
+	respondWithJSON(w, 202, struct{}{})
+}
+
+func (cfg *apiConfig) handlerConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	resetReq := passwordResetRequest{}
+	err := decoder.Decode(&resetReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(resetReq.Password)
+	if err != nil || len(hashedPassword) == 0 {
+		log.Printf("Error hashing password: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	userId, err := qtx.UsePasswordResetToken(r.Context(), auth.HashOneTimeToken(resetReq.Token, cfg.tokenPepper))
+	if err == sql.ErrNoRows {
+		respondWithError(w, 400, "Invalid or expired reset token")
+		return
+	} else if err != nil {
+		log.Printf("Error using reset token: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	_, err = qtx.UpdateUserPassword(r.Context(), database.UpdateUserPasswordParams{
+		ID:             userId,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		log.Printf("Error updating password: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = qtx.RevokeUserRefreshTokens(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error revoking refresh tokens: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = qtx.DeletePasswordResetTokens(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error deleting reset tokens: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error resetting password: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 204, User{})
+}
+
+func (cfg *apiConfig) handlerVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	verifyReq := emailVerificationRequest{}
+	err := decoder.Decode(&verifyReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	verification, err := qtx.UseEmailVerificationToken(r.Context(), auth.HashOneTimeToken(verifyReq.Token, cfg.tokenPepper))
+	if err == sql.ErrNoRows {
+		respondWithError(w, 400, "Invalid or expired verification token")
+		return
+	} else if err != nil {
+		log.Printf("Error using verification token: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	// The token is bound to the address it was mailed to, so a link for an
+	// address the user has since changed away from verifies nothing.
+	user, err := qtx.VerifyUserEmail(r.Context(), database.VerifyUserEmailParams{
+		ID:    verification.UserID,
+		Email: verification.Email,
+	})
+	if err == sql.ErrNoRows {
+		respondWithError(w, 400, "Invalid or expired verification token")
+		return
+	} else if err != nil {
+		log.Printf("Error verifying email: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error verifying email: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 200, User{
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		Handle:        user.Handle.String,
+		IsChirpyRed:   user.IsChirpyRed,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		Subscription:  cfg.userSubscription(r.Context(), user.ID),
+	})
+}
+
+func (cfg *apiConfig) handlerResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		respondWithError(w, 409, "Email address is already verified")
+		return
+	}
+
+	err = cfg.sendEmailVerification(r.Context(), user)
+	if err != nil {
+		log.Printf("Error sending email verification: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 202, struct{}{})
+}
+
+// sendEmailVerification mails user a link confirming their current address.
+func (cfg *apiConfig) sendEmailVerification(ctx context.Context, user database.User) error {
+	verifyToken, err := auth.MakeOneTimeToken()
+	if err != nil {
+		return err
+	}
+	err = cfg.db.CreateEmailVerificationToken(ctx, database.CreateEmailVerificationTokenParams{
+		TokenHash: auth.HashOneTimeToken(verifyToken, cfg.tokenPepper),
+		UserID:    user.ID,
+		Email:     user.Email,
+	})
+	if err != nil {
+		return err
+	}
+
+	cfg.sendMail(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your Chirpy email address",
+		Body: fmt.Sprintf("Confirm this is your email address by opening the link below "+
+			"within the next 24 hours:\n\n"+
+			"%s/app/verify-email?token=%s\n", cfg.publicURL, verifyToken),
+	})
+	return nil
+}
+
+// sendMail delivers msg in the background so that slow mail servers neither
+// hold up the request nor make response times reveal whether mail was sent.
+func (cfg *apiConfig) sendMail(ctx context.Context, msg mail.Message) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mailSendTimeout)
+	go func() {
+		defer cancel()
+		if err := cfg.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Error sending mail to %s: %s", msg.To, err)
+		}
+	}()
+}
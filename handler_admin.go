@@ -1,7 +1,9 @@
 package main
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"internal/auth"
 	"log"
 	"net/http"
 )
@@ -43,3 +45,47 @@ func (cfg *apiConfig) handlerReset(w http.ResponseWriter, r *http.Request) {
 		log.Fatalf("Error wiping database: %v", err)
 	}
 }
+
+// requireAdmin checks for the ADMIN_API_KEY in an "Authorization: ApiKey"
+// header. With no key configured every admin API call is refused.
+func (cfg *apiConfig) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	adminKey, err := auth.GetAPIKey(r.Header)
+	if err != nil || cfg.adminKey == "" ||
+		subtle.ConstantTimeCompare([]byte(adminKey), []byte(cfg.adminKey)) != 1 {
+		respondWithError(w, 401, "Invalid key")
+		return false
+	}
+	return true
+}
+
+// handlerClearLockout lifts the login throttle on the account given by the
+// email query parameter and/or the client address given by ip.
+func (cfg *apiConfig) handlerClearLockout(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	var keys []string
+	if email := r.URL.Query().Get("email"); email != "" {
+		keys = append(keys, accountThrottleKey(email))
+	}
+	if ip := r.URL.Query().Get("ip"); ip != "" {
+		keys = append(keys, ipThrottleKey(ip))
+	}
+	if len(keys) == 0 {
+		respondWithError(w, 400, "Provide an email or ip to clear")
+		return
+	}
+
+	for _, key := range keys {
+		err := cfg.db.ClearLoginThrottle(r.Context(), key)
+		if err != nil {
+			log.Printf("Error clearing login throttle: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		log.Printf("Admin cleared login throttle for %s", key)
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
@@ -1,74 +1,140 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"internal/auth"
+	"internal/chirptext"
 	"internal/database"
+	"internal/mail"
+	"internal/media"
+	"internal/stream"
 	"log"
 	"net/http"
-	"sort"
-	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// uniqueViolation is the Postgres error code for a unique constraint
+// failure.
+const uniqueViolation = "23505"
+
 type apiConfig struct {
 	fileserverHits atomic.Int32
 	platform       string
 	db             *database.Queries
-	jwtSecret      string
+	dbConn         *sql.DB
+	jwtKeys        *auth.KeySet
+	tokenPepper    string
+	adminKey       string
 	polkaKey       string
+	polkaSecret    string
+	mailer         mail.Mailer
+	publicURL      string
+	// requireVerifiedEmail blocks posting chirps until the author has
+	// confirmed their email address.
+	requireVerifiedEmail bool
+	// trustProxyHeaders takes the client address from X-Forwarded-For.
+	trustProxyHeaders bool
+	tiers             tierConfig
+	// contentFilter is swapped out whole when an admin reloads
+	// contentFilterFile.
+	contentFilter     atomic.Pointer[chirptext.Filter]
+	contentFilterFile string
+	blobs             media.BlobStore
+	mediaLimits       media.Limits
+	// events carries chirp changes to streaming clients.
+	events stream.Broker
 }
 
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Email        string    `json:"email"`
-	Token        string    `json:"token"`
-	RefreshToken string    `json:"refresh_token"`
-	IsChirpyRed  bool      `json:"is_chirpy_red"`
+	ID            uuid.UUID `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Email         string    `json:"email"`
+	Handle        string    `json:"handle,omitempty"`
+	Token         string    `json:"token"`
+	RefreshToken  string    `json:"refresh_token"`
+	IsChirpyRed   bool      `json:"is_chirpy_red"`
+	EmailVerified bool      `json:"email_verified"`
+	// Subscription is omitted for users who have never had Chirpy Red.
+	Subscription *subscriptionResponse `json:"subscription,omitempty"`
 }
 
 type chirpPost struct {
 	Body   string    `json:"body"`
 	UserID uuid.UUID `json:"user_id"`
+	// PublishAt schedules the chirp instead of posting it straight away.
+	PublishAt *time.Time `json:"publish_at"`
+	InReplyTo *uuid.UUID `json:"in_reply_to"`
+	// AttachmentIDs are uploads from POST /api/attachments, in the order
+	// they should be shown.
+	AttachmentIDs []uuid.UUID `json:"attachment_ids"`
 }
 
 type chirpResponse struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID           uuid.UUID  `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Body         string     `json:"body"`
+	UserID       uuid.UUID  `json:"user_id"`
+	Edited       bool       `json:"edited"`
+	EditCount    int32      `json:"edit_count"`
+	InReplyTo    *uuid.UUID `json:"in_reply_to"`
+	ReplyCount   int32      `json:"reply_count"`
+	LikeCount    int32      `json:"like_count"`
+	RechirpCount int32      `json:"rechirp_count"`
+	// RechirpedBy is set on chirps that appear in an author feed because
+	// that author rechirped them.
+	RechirpedBy *uuid.UUID           `json:"rechirped_by,omitempty"`
+	Attachments []attachmentResponse `json:"attachments,omitempty"`
 }
 
-type polkaRequest struct {
-	Event string `json:"event"`
-	Data  struct {
-		UserID string `json:"user_id"`
-	} `json:"data"`
+type chirpsPage struct {
+	Chirps     []chirpResponse `json:"chirps"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 type userRequest struct {
 	Email            string `json:"email"`
 	Password         string `json:"password"`
 	ExpiresInSeconds int    `json:"expires_in_seconds"`
+	// Handle is left unchanged when omitted and cleared when empty.
+	Handle *string `json:"handle"`
 }
 
 type errorResponse struct {
 	Error string `json:"error"`
 }
 
+// chirpLengthErrorResponse spells out the length rules so clients can show
+// how much needs trimming.
+type chirpLengthErrorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Length    int    `json:"length"`
+	Limit     int    `json:"limit"`
+	OverBy    int    `json:"over_by"`
+	URLWeight int    `json:"url_weight"`
+}
+
 func handlerReadiness(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "text/plain; charset=utf-8")
 	w.WriteHeader(200)
 	w.Write([]byte("OK"))
 }
 
+func (cfg *apiConfig) handlerJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJSON(w, 200, cfg.jwtKeys.JWKS())
+}
+
 func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request) {
 	decoder := json.NewDecoder(r.Body)
 	newUserReq := userRequest{}
@@ -96,12 +162,19 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	err = cfg.sendEmailVerification(r.Context(), user)
+	if err != nil {
+		log.Printf("Error sending email verification: %s", err)
+	}
+
 	respondWithJSON(w, 201, User{
-		ID:          user.ID,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed,
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		Handle:        user.Handle.String,
+		IsChirpyRed:   user.IsChirpyRed,
+		EmailVerified: user.EmailVerifiedAt.Valid,
 	})
 }
 
@@ -115,6 +188,12 @@ func (cfg *apiConfig) handlerLoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	accountKey := accountThrottleKey(userReq.Email)
+	ipKey := ipThrottleKey(cfg.clientIP(r))
+	if !cfg.reserveLoginAttempt(w, r, accountKey, ipKey) {
+		return
+	}
+
 	user, err := cfg.db.GetUserFromEmail(r.Context(), userReq.Email)
 	if err != nil {
 		log.Printf("Error logging in: %s", err)
@@ -129,7 +208,57 @@ func (cfg *apiConfig) handlerLoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := auth.MakeJWT(user.ID, cfg.jwtSecret, time.Duration(3600)*time.Second)
+	cfg.releaseLoginAttempt(r.Context(), accountKey, ipKey)
+
+	if auth.NeedsRehash(user.HashedPassword) {
+		cfg.rehashPassword(r.Context(), user.ID, userReq.Password)
+	}
+
+	totpSecret, err := cfg.db.GetTotpSecret(r.Context(), user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error retrieving TOTP secret: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if err == nil && totpSecret.ConfirmedAt.Valid {
+		mfaToken, err := auth.MakeMFAChallengeJWT(user.ID, cfg.jwtKeys, mfaChallengeExpiry)
+		if err != nil {
+			log.Printf("Error creating token: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		respondWithJSON(w, 200, mfaChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		})
+		return
+	}
+
+	cfg.respondWithSession(w, r, user)
+}
+
+// rehashPassword upgrades a stored hash to the current algorithm and
+// parameters while the plaintext is at hand. Failure is not fatal to the
+// login; the upgrade is simply retried next time.
+func (cfg *apiConfig) rehashPassword(ctx context.Context, userID uuid.UUID, password string) {
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		log.Printf("Error rehashing password: %s", err)
+		return
+	}
+	_, err = cfg.db.UpdateUserPassword(ctx, database.UpdateUserPasswordParams{
+		ID:             userID,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		log.Printf("Error storing rehashed password: %s", err)
+	}
+}
+
+// respondWithSession issues a new access token and refresh token family for a
+// user who has fully authenticated.
+func (cfg *apiConfig) respondWithSession(w http.ResponseWriter, r *http.Request, user database.User) {
+	token, err := auth.MakeJWT(user.ID, cfg.jwtKeys, time.Duration(3600)*time.Second)
 	if err != nil {
 		log.Printf("Error creating token: %s", err)
 		respondWithError(w, 500, "Something went wrong")
@@ -143,8 +272,9 @@ func (cfg *apiConfig) handlerLoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	_, err = cfg.db.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
-		Token:  refresh_token,
-		UserID: user.ID,
+		TokenHash: auth.HashRefreshToken(refresh_token, cfg.tokenPepper),
+		UserID:    user.ID,
+		FamilyID:  uuid.New(),
 	})
 	if err != nil {
 		log.Printf("Error creating refresh token: %s", err)
@@ -153,13 +283,16 @@ func (cfg *apiConfig) handlerLoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	respondWithJSON(w, 200, User{
-		ID:           user.ID,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
-		Email:        user.Email,
-		Token:        token,
-		RefreshToken: refresh_token,
-		IsChirpyRed:  user.IsChirpyRed,
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		Handle:        user.Handle.String,
+		Token:         token,
+		RefreshToken:  refresh_token,
+		IsChirpyRed:   user.IsChirpyRed,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		Subscription:  cfg.userSubscription(r.Context(), user.ID),
 	})
 }
 
@@ -180,13 +313,25 @@ func (cfg *apiConfig) handlerUpdateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
 	if err != nil {
 		log.Printf("Invalid token: %s", err)
 		respondWithError(w, 401, "Unauthorized")
 		return
 	}
 
+	currentUser, err := cfg.db.GetUser(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	if userReq.Handle != nil && *userReq.Handle != "" && !chirptext.ValidHandle(*userReq.Handle) {
+		respondWithError(w, 400, "Handles are 1 to 30 letters, digits or underscores")
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(userReq.Password)
 	if err != nil || len(hashedPassword) == 0 {
 		log.Printf("Error hashing password: %s", err)
@@ -194,7 +339,16 @@ func (cfg *apiConfig) handlerUpdateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	updatedUser, err := cfg.db.UpdateUser(r.Context(), database.UpdateUserParams{
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	updatedUser, err := qtx.UpdateUser(r.Context(), database.UpdateUserParams{
 		Email:          userReq.Email,
 		HashedPassword: hashedPassword,
 		ID:             userId,
@@ -205,93 +359,139 @@ func (cfg *apiConfig) handlerUpdateUser(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	respondWithJSON(w, 200, User{
-		ID:          updatedUser.ID,
-		CreatedAt:   updatedUser.CreatedAt,
-		UpdatedAt:   updatedUser.UpdatedAt,
-		Email:       updatedUser.Email,
-		IsChirpyRed: updatedUser.IsChirpyRed,
-	})
-}
+	if userReq.Handle != nil {
+		updatedUser, err = qtx.SetUserHandle(r.Context(), database.SetUserHandleParams{
+			Handle: sql.NullString{String: *userReq.Handle, Valid: *userReq.Handle != ""},
+			ID:     userId,
+		})
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			respondWithError(w, 409, "That handle is already taken")
+			return
+		} else if err != nil {
+			log.Printf("Error updating database: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+	}
 
-func (cfg *apiConfig) handlerUpgradeUser(w http.ResponseWriter, r *http.Request) {
-	decoder := json.NewDecoder(r.Body)
-	polkaReq := polkaRequest{}
-	err := decoder.Decode(&polkaReq)
+	err = tx.Commit()
 	if err != nil {
-		log.Printf("Error parsing request: %s", err)
+		log.Printf("Error updating database: %s", err)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
 
-	polkaKey, err := auth.GetAPIKey(r.Header)
-
-	if polkaKey != cfg.polkaKey {
-		log.Printf("Invalid apiKey: %s", err)
-		respondWithError(w, 401, "Invalid key")
-		return
-	} else if err != nil {
-		log.Printf("Error processing authorization: %s", err)
-		respondWithError(w, 500, "Something went wrong")
-		return
+	if updatedUser.Email != currentUser.Email {
+		err = cfg.sendEmailVerification(r.Context(), updatedUser)
+		if err != nil {
+			log.Printf("Error sending email verification: %s", err)
+		}
 	}
 
-	if polkaReq.Event != "user.upgraded" {
-		respondWithJSON(w, 204, User{})
+	respondWithJSON(w, 200, User{
+		ID:            updatedUser.ID,
+		CreatedAt:     updatedUser.CreatedAt,
+		UpdatedAt:     updatedUser.UpdatedAt,
+		Email:         updatedUser.Email,
+		Handle:        updatedUser.Handle.String,
+		IsChirpyRed:   updatedUser.IsChirpyRed,
+		EmailVerified: updatedUser.EmailVerifiedAt.Valid,
+		Subscription:  cfg.userSubscription(r.Context(), updatedUser.ID),
+	})
+}
+
+func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
+	refresh_token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
 		return
 	}
 
-	userID, err := uuid.Parse(polkaReq.Data.UserID)
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
 	if err != nil {
-		log.Printf("Error parsing user id: %s", err)
+		log.Printf("Error starting transaction: %s", err)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	_, err = cfg.db.UpgradeUser(r.Context(), userID)
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	token_hash := auth.HashRefreshToken(refresh_token, cfg.tokenPepper)
+	rotated_token, err := qtx.RotateRefreshToken(r.Context(), token_hash)
 	if err == sql.ErrNoRows {
-		log.Printf("User does not exist: %s", err)
-		respondWithError(w, 404, "User not found")
+		tx.Rollback()
+		cfg.revokeReusedRefreshToken(r.Context(), token_hash)
+		respondWithError(w, 401, "Unauthorized")
 		return
 	} else if err != nil {
-		log.Printf("Error upgrading user: %s", err)
+		log.Printf("Error retrieving token: %s", err)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
 
-	respondWithJSON(w, 204, User{})
-}
-
-func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
-	refresh_token, err := auth.GetBearerToken(r.Header)
+	new_refresh_token, err := auth.MakeRefreshToken()
 	if err != nil {
-		log.Printf("Invalid token: %s", err)
-		respondWithError(w, 401, "Unauthorized")
+		log.Printf("Error creating refresh token: %s", err)
+		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-
-	active_token, err := cfg.db.GetActiveRefreshToken(r.Context(), refresh_token)
-	if err == sql.ErrNoRows {
-		log.Printf("Invalid token: %s", err)
-		respondWithError(w, 401, "Unauthorized")
-		return
-	} else if err != nil {
-		log.Printf("Error retrieving token: %s", err)
+	_, err = qtx.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
+		TokenHash:       auth.HashRefreshToken(new_refresh_token, cfg.tokenPepper),
+		UserID:          rotated_token.UserID,
+		FamilyID:        rotated_token.FamilyID,
+		ParentTokenHash: sql.NullString{String: rotated_token.TokenHash, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error creating refresh token: %s", err)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
 
-	token, err := auth.MakeJWT(active_token.UserID, cfg.jwtSecret, time.Duration(3600)*time.Second)
+	token, err := auth.MakeJWT(rotated_token.UserID, cfg.jwtKeys, time.Duration(3600)*time.Second)
 	if err != nil {
 		log.Printf("Error creating token: %s", err)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error rotating refresh token: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
 	respondWithJSON(w, 200, User{
-		Token: token,
+		Token:        token,
+		RefreshToken: new_refresh_token,
 	})
 }
 
+// revokeReusedRefreshToken is called when a presented refresh token could not
+// be rotated. A token that exists but was already revoked has been used
+// twice, so every token descended from the same login is treated as stolen.
+func (cfg *apiConfig) revokeReusedRefreshToken(ctx context.Context, token_hash string) {
+	stale_token, err := cfg.db.GetRefreshToken(ctx, token_hash)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error retrieving token: %s", err)
+		}
+		return
+	}
+	if !stale_token.RevokedAt.Valid {
+		return
+	}
+
+	err = cfg.db.RevokeRefreshTokenFamily(ctx, stale_token.FamilyID)
+	if err != nil {
+		log.Printf("Error revoking token family %s: %s", stale_token.FamilyID, err)
+		return
+	}
+	log.Printf("SECURITY: refresh token reuse detected for user %s, revoked token family %s", stale_token.UserID, stale_token.FamilyID)
+}
+
 func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 	refresh_token, err := auth.GetBearerToken(r.Header)
 	if err != nil {
@@ -300,7 +500,7 @@ func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = cfg.db.RevokeRefreshToken(r.Context(), refresh_token)
+	err = cfg.db.RevokeRefreshToken(r.Context(), auth.HashRefreshToken(refresh_token, cfg.tokenPepper))
 	if err != nil {
 		log.Printf("Error revoking token: %s", err)
 		respondWithError(w, 500, "Something went wrong")
@@ -325,34 +525,195 @@ func (cfg *apiConfig) handlerPostChirp(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
 	if err != nil {
 		log.Printf("Invalid token: %s", err)
 		respondWithError(w, 401, "Unauthorized")
 		return
 	}
 
-	if len(chirp.Body) > 140 {
-		respondWithError(w, 400, "Chirp is too long")
+	author, err := cfg.db.GetUser(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if cfg.requireVerifiedEmail && !author.EmailVerifiedAt.Valid {
+		respondWithError(w, 403, "Verify your email address before posting")
+		return
+	}
+	perks := cfg.tiers.forUser(author)
+
+	filtered, ok := cfg.prepareChirpBody(w, chirp.Body, perks)
+	if !ok {
+		return
+	}
+	err = validateAttachmentIDs(chirp.AttachmentIDs)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	if perks.ChirpsPerHour > 0 {
+		// Holding the author's row until the chirp is stored stops parallel
+		// posts all counting the same total.
+		err = qtx.LockUser(r.Context(), userId)
+		if err != nil {
+			log.Printf("Error locking user: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		posted, err := qtx.CountRecentPosts(r.Context(), userId)
+		if err != nil {
+			log.Printf("Error counting recent chirps: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		if posted >= int64(perks.ChirpsPerHour) {
+			respondWithError(w, 429, "Too many chirps, try again later")
+			return
+		}
+	}
+
+	if chirp.PublishAt != nil {
+		if !perks.CanScheduleChirps {
+			respondWithError(w, 403, "Scheduling chirps requires Chirpy Red")
+			return
+		}
+		if !chirp.PublishAt.After(time.Now()) {
+			respondWithError(w, 400, "publish_at must be in the future")
+			return
+		}
+		// The chirp being replied to could be gone by the time a reply
+		// is published.
+		if chirp.InReplyTo != nil {
+			respondWithError(w, 400, "Replies cannot be scheduled")
+			return
+		}
+		// Uploads would be swept up before a chirp far in the future is
+		// published.
+		if len(chirp.AttachmentIDs) > 0 {
+			respondWithError(w, 400, "Chirps with attachments cannot be scheduled")
+			return
+		}
+		scheduled, err := qtx.CreateScheduledChirp(r.Context(), database.CreateScheduledChirpParams{
+			Body:      filtered.Body,
+			UserID:    userId,
+			PublishAt: chirp.PublishAt.UTC(),
+		})
+		if err != nil {
+			log.Printf("Error scheduling chirp: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		err = tx.Commit()
+		if err != nil {
+			log.Printf("Error scheduling chirp: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		if filtered.Flagged {
+			cfg.flagChirpForReview(r.Context(), scheduled.ID, filtered.Matches)
+		}
+		respondWithJSON(w, 202, newScheduledChirpResponse(scheduled))
 		return
 	}
 
-	newChirp, err := cfg.db.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   replaceProfanity(chirp.Body),
-		UserID: userId,
+	inReplyTo := uuid.NullUUID{}
+	if chirp.InReplyTo != nil {
+		inReplyTo = uuid.NullUUID{UUID: *chirp.InReplyTo, Valid: true}
+		// Bumping the count first also locks the parent, so it cannot be
+		// deleted before the reply is stored.
+		updated, err := qtx.IncrementReplyCount(r.Context(), inReplyTo.UUID)
+		if err != nil {
+			log.Printf("Error updating reply count: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		if updated == 0 {
+			respondWithError(w, 400, "The chirp being replied to does not exist")
+			return
+		}
+	}
+
+	newChirp, err := qtx.CreateChirp(r.Context(), database.CreateChirpParams{
+		Body:      filtered.Body,
+		UserID:    userId,
+		InReplyTo: inReplyTo,
 	})
 	if err != nil {
 		log.Printf("Error creating chirp: %s", err)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	respondWithJSON(w, 201, chirpResponse{
-		ID:        newChirp.ID,
-		CreatedAt: newChirp.CreatedAt,
-		UpdatedAt: newChirp.UpdatedAt,
-		Body:      newChirp.Body,
-		UserID:    newChirp.UserID,
-	})
+
+	mentioned, err := indexChirpEntities(r.Context(), qtx, newChirp)
+	if err != nil {
+		log.Printf("Error indexing chirp tags and mentions: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	if len(chirp.AttachmentIDs) > 0 {
+		attached, err := attachToChirp(r.Context(), qtx, newChirp, chirp.AttachmentIDs)
+		if err != nil {
+			log.Printf("Error attaching uploads to chirp: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		if !attached {
+			respondWithError(w, 400, "Attachments must be your own recent uploads and not already on a chirp")
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error creating chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if filtered.Flagged {
+		cfg.flagChirpForReview(r.Context(), newChirp.ID, filtered.Matches)
+	}
+	cfg.publishChirpEvent(r.Context(), chirpCreatedEvent, newChirp)
+	cfg.notifyNewChirp(r.Context(), newChirp, mentioned)
+	respondWithJSON(w, 201, cfg.newChirpResponseWithAttachments(r.Context(), newChirp))
+}
+
+// prepareChirpBody normalises a new or edited chirp body, checks it against
+// the author's length limit and runs it through the content filter. If the
+// body is refused the error response has already been written.
+func (cfg *apiConfig) prepareChirpBody(w http.ResponseWriter, body string, perks entitlements) (chirptext.FilterResult, bool) {
+	body = chirptext.Normalize(body)
+	var lengthErr *chirptext.LengthError
+	if errors.As(chirptext.Validate(body, perks.MaxChirpLength), &lengthErr) {
+		respondWithJSON(w, 400, chirpLengthErrorResponse{
+			Error:     fmt.Sprintf("Chirp is too long, the limit is %d characters", lengthErr.Limit),
+			Code:      "chirp_too_long",
+			Length:    lengthErr.Length,
+			Limit:     lengthErr.Limit,
+			OverBy:    lengthErr.OverBy(),
+			URLWeight: chirptext.URLWeight,
+		})
+		return chirptext.FilterResult{}, false
+	}
+
+	filtered := cfg.contentFilter.Load().Apply(body)
+	if filtered.Rejected {
+		respondWithError(w, 400, "Chirp contains words that are not allowed")
+		return chirptext.FilterResult{}, false
+	}
+	return filtered, true
 }
 
 func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request) {
@@ -381,7 +742,7 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
 	if err != nil {
 		log.Printf("Invalid token: %s", err)
 		respondWithError(w, 401, "Unauthorized")
@@ -393,56 +754,101 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = cfg.db.DeleteChirp(r.Context(), chirp.ID)
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// The chirp's images go with it rather than staying served until the
+	// sweeper finds them.
+	attachmentIds, err := qtx.DeleteChirpAttachments(r.Context(), chirp.ID)
+	if err != nil {
+		log.Printf("Error deleting chirp attachments: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	err = qtx.DeleteChirp(r.Context(), chirp.ID)
+	if err != nil {
+		log.Printf("Error deleting chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if chirp.InReplyTo.Valid {
+		err = qtx.DecrementReplyCount(r.Context(), chirp.InReplyTo.UUID)
+		if err != nil {
+			log.Printf("Error updating reply count: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		log.Printf("Error deleting chirp: %s", err)
 		respondWithError(w, 500, "Something went wrong")
 		return
 	}
+	for _, id := range attachmentIds {
+		cfg.deleteAttachmentBlobs(r.Context(), id)
+	}
+	cfg.publishChirpEvent(r.Context(), chirpDeletedEvent, chirp)
 
 	respondWithJSON(w, 204, chirpResponse{})
 }
 
 func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
-	authorId, _ := uuid.Parse(r.URL.Query().Get("author_id"))
-	sortBy := r.URL.Query().Get("sort")
+	query := r.URL.Query()
+	authorId := uuid.NullUUID{}
+	if authorParam := query.Get("author_id"); authorParam != "" {
+		id, err := uuid.Parse(authorParam)
+		if err != nil {
+			respondWithError(w, 400, "Invalid author_id")
+			return
+		}
+		authorId = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	sortBy := query.Get("sort")
 
-	chirpResponses, err := cfg.db.GetChirps(r.Context())
+	page, err := parsePageParams(query)
 	if err != nil {
-		log.Printf("Error retrieving chirp: %s", err)
-		respondWithError(w, 500, "Something went wrong")
+		respondWithError(w, 400, err.Error())
 		return
 	}
-	var chirps []chirpResponse
-	if authorId != uuid.Nil {
-		for _, chirp := range chirpResponses {
-			if chirp.UserID == authorId {
-				chirps = append(chirps, chirpResponse{
-					ID:        chirp.ID,
-					CreatedAt: chirp.CreatedAt,
-					UpdatedAt: chirp.UpdatedAt,
-					Body:      chirp.Body,
-					UserID:    chirp.UserID,
-				})
-			}
-		}
-	} else {
-		for _, chirp := range chirpResponses {
-			chirps = append(chirps, chirpResponse{
-				ID:        chirp.ID,
-				CreatedAt: chirp.CreatedAt,
-				UpdatedAt: chirp.UpdatedAt,
-				Body:      chirp.Body,
-				UserID:    chirp.UserID,
-			})
-		}
+
+	if authorId.Valid {
+		cfg.respondWithAuthorFeed(w, r, authorId.UUID, sortBy, page)
+		return
 	}
+
+	var dbChirps []database.Chirp
 	if sortBy == "desc" {
-		sort.Slice(chirps, func(i, j int) bool { return chirps[i].CreatedAt.After(chirps[j].CreatedAt) })
+		dbChirps, err = cfg.db.GetChirpsDesc(r.Context(), database.GetChirpsDescParams{
+			AuthorID:       authorId,
+			AfterCreatedAt: page.afterCreatedAt(),
+			AfterID:        page.afterID(),
+			Limit:          page.fetchLimit(),
+		})
 	} else {
-		sort.Slice(chirps, func(i, j int) bool { return chirps[i].CreatedAt.Before(chirps[j].CreatedAt) })
+		dbChirps, err = cfg.db.GetChirpsAsc(r.Context(), database.GetChirpsAscParams{
+			AuthorID:       authorId,
+			AfterCreatedAt: page.afterCreatedAt(),
+			AfterID:        page.afterID(),
+			Limit:          page.fetchLimit(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
 	}
-	respondWithJSON(w, 200, chirps)
+
+	response := newChirpsPage(dbChirps, page.Limit)
+	cfg.loadAttachments(r.Context(), response.Chirps)
+	respondWithJSON(w, 200, response)
 }
 
 func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
@@ -464,13 +870,41 @@ func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondWithJSON(w, 200, chirpResponse{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
-	})
+	respondWithJSON(w, 200, cfg.newChirpResponseWithAttachments(r.Context(), chirp))
+}
+
+func newChirpResponse(chirp database.Chirp) chirpResponse {
+	response := chirpResponse{
+		ID:           chirp.ID,
+		CreatedAt:    chirp.CreatedAt,
+		UpdatedAt:    chirp.UpdatedAt,
+		Body:         chirp.Body,
+		UserID:       chirp.UserID,
+		Edited:       chirp.EditCount > 0,
+		EditCount:    chirp.EditCount,
+		ReplyCount:   chirp.ReplyCount,
+		LikeCount:    chirp.LikeCount,
+		RechirpCount: chirp.RechirpCount,
+	}
+	if chirp.InReplyTo.Valid {
+		response.InReplyTo = &chirp.InReplyTo.UUID
+	}
+	return response
+}
+
+// newChirpsPage trims the extra look-ahead row fetched by the paginated
+// queries and turns it into the cursor for the following page.
+func newChirpsPage(dbChirps []database.Chirp, limit int32) chirpsPage {
+	page := chirpsPage{Chirps: make([]chirpResponse, 0, len(dbChirps))}
+	if int32(len(dbChirps)) > limit {
+		dbChirps = dbChirps[:limit]
+		last := dbChirps[len(dbChirps)-1]
+		page.NextCursor = pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}.encode()
+	}
+	for _, chirp := range dbChirps {
+		page.Chirps = append(page.Chirps, newChirpResponse(chirp))
+	}
+	return page
 }
 
 func respondWithError(w http.ResponseWriter, code int, msg string) {
@@ -484,17 +918,3 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(payload)
 }
-
-func replaceProfanity(body string) string {
-	words := strings.Split(body, " ")
-	for i := range words {
-		switch strings.ToLower(words[i]) {
-		case
-			"kerfuffle",
-			"sharbert",
-			"fornax":
-			words[i] = "****"
-		}
-	}
-	return strings.Join(words, " ")
-}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"internal/auth"
+	"internal/database"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	totpIssuer         = "Chirpy"
+	mfaChallengeExpiry = 5 * time.Minute
+)
+
+type totpEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type mfaChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+type mfaRequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+func (cfg *apiConfig) handlerEnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	secret, err := auth.MakeTOTPSecret()
+	if err != nil {
+		log.Printf("Error creating TOTP secret: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	_, err = cfg.db.CreateTotpSecret(r.Context(), database.CreateTotpSecretParams{
+		UserID: user.ID,
+		Secret: secret,
+	})
+	if err == sql.ErrNoRows {
+		respondWithError(w, 409, "Two-factor authentication is already enabled")
+		return
+	} else if err != nil {
+		log.Printf("Error storing TOTP secret: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 201, totpEnrollment{
+		Secret: secret,
+		URI:    auth.TOTPProvisioningURI(secret, totpIssuer, user.Email),
+	})
+}
+
+func (cfg *apiConfig) handlerConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	mfaReq := mfaRequest{}
+	err := decoder.Decode(&mfaReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	totpSecret, err := cfg.db.GetTotpSecret(r.Context(), userId)
+	if err == sql.ErrNoRows {
+		respondWithError(w, 404, "No pending two-factor enrollment")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving TOTP secret: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if totpSecret.ConfirmedAt.Valid {
+		respondWithError(w, 409, "Two-factor authentication is already enabled")
+		return
+	}
+
+	step, err := auth.ValidateTOTP(totpSecret.Secret, mfaReq.Code, time.Now())
+	if err != nil {
+		respondWithError(w, 400, "Invalid code")
+		return
+	}
+
+	recoveryCodes, err := auth.MakeRecoveryCodes()
+	if err != nil {
+		log.Printf("Error creating recovery codes: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	confirmed, err := qtx.ConfirmTotpSecret(r.Context(), database.ConfirmTotpSecretParams{
+		UserID:       userId,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		log.Printf("Error confirming TOTP secret: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if confirmed == 0 {
+		respondWithError(w, 409, "Two-factor authentication is already enabled")
+		return
+	}
+
+	err = qtx.DeleteRecoveryCodes(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error deleting recovery codes: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	for _, code := range recoveryCodes {
+		err = qtx.CreateRecoveryCode(r.Context(), database.CreateRecoveryCodeParams{
+			UserID:   userId,
+			CodeHash: auth.HashRecoveryCode(code, cfg.tokenPepper),
+		})
+		if err != nil {
+			log.Printf("Error storing recovery code: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error confirming TOTP secret: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 200, recoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// handlerLoginMFA exchanges the challenge token handed out by
+// handlerLoginUser, plus either a current TOTP code or an unused recovery
+// code, for a normal session.
+func (cfg *apiConfig) handlerLoginMFA(w http.ResponseWriter, r *http.Request) {
+	decoder := json.NewDecoder(r.Body)
+	mfaReq := mfaRequest{}
+	err := decoder.Decode(&mfaReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	userId, err := auth.ValidateMFAChallengeJWT(mfaReq.MFAToken, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	mfaKey := mfaThrottleKey(userId.String())
+	ipKey := ipThrottleKey(cfg.clientIP(r))
+	if !cfg.reserveLoginAttempt(w, r, mfaKey, ipKey) {
+		return
+	}
+
+	if mfaReq.RecoveryCode != "" {
+		used, err := cfg.db.UseRecoveryCode(r.Context(), database.UseRecoveryCodeParams{
+			UserID:   userId,
+			CodeHash: auth.HashRecoveryCode(mfaReq.RecoveryCode, cfg.tokenPepper),
+		})
+		if err != nil {
+			log.Printf("Error using recovery code: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		if used == 0 {
+			respondWithError(w, 401, "Invalid code")
+			return
+		}
+	} else {
+		totpSecret, err := cfg.db.GetTotpSecret(r.Context(), userId)
+		if err != nil {
+			log.Printf("Error retrieving TOTP secret: %s", err)
+			respondWithError(w, 401, "Invalid code")
+			return
+		}
+		step, err := auth.ValidateTOTP(totpSecret.Secret, mfaReq.Code, time.Now())
+		if err != nil {
+			respondWithError(w, 401, "Invalid code")
+			return
+		}
+		// Recording the step means a code observed in transit cannot be
+		// replayed within its validity window.
+		used, err := cfg.db.UseTotpStep(r.Context(), database.UseTotpStepParams{
+			UserID:       userId,
+			LastUsedStep: step,
+		})
+		if err != nil {
+			log.Printf("Error recording TOTP step: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		if used == 0 {
+			respondWithError(w, 401, "Invalid code")
+			return
+		}
+	}
+
+	cfg.releaseLoginAttempt(r.Context(), mfaKey, ipKey)
+
+	user, err := cfg.db.GetUser(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	cfg.respondWithSession(w, r, user)
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"internal/auth"
+	"internal/database"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	polkaSignatureTolerance = 5 * time.Minute
+	maxPolkaBodyBytes       = 1 << 20
+)
+
+// Every stored event starts out "received" and ends up in one of the last
+// three. While it is "processing" other deliveries of it are turned away;
+// an event left there by a crash can be claimed again after five minutes.
+const (
+	polkaEventReceived   = "received"
+	polkaEventProcessing = "processing"
+	polkaEventProcessed  = "processed"
+	polkaEventIgnored    = "ignored"
+	polkaEventFailed     = "failed"
+)
+
+const (
+	polkaUserUpgraded        = "user.upgraded"
+	polkaUserDowngraded      = "user.downgraded"
+	polkaSubscriptionRenewed = "subscription.renewed"
+	polkaPaymentFailed       = "subscription.payment_failed"
+)
+
+var (
+	errPolkaEventIgnored = errors.New("event ignored")
+	errPolkaUserNotFound = errors.New("user not found")
+)
+
+type polkaRequest struct {
+	ID    string `json:"id"`
+	Event string `json:"event"`
+	Data  struct {
+		UserID string `json:"user_id"`
+		// The billing period is optional; without it a period runs for a
+		// month from the upgrade, or from the end of the one being renewed.
+		PeriodStart *time.Time `json:"period_start"`
+		PeriodEnd   *time.Time `json:"period_end"`
+	} `json:"data"`
+}
+
+type polkaEventResponse struct {
+	ID          string          `json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	LastError   string          `json:"last_error,omitempty"`
+	Attempts    int32           `json:"attempts"`
+	ProcessedAt *time.Time      `json:"processed_at"`
+}
+
+func (cfg *apiConfig) handlerPolkaWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPolkaBodyBytes))
+	if err != nil {
+		log.Printf("Error reading request: %s", err)
+		respondWithError(w, 400, "Invalid request body")
+		return
+	}
+
+	if !cfg.authenticatePolka(w, r, body) {
+		return
+	}
+
+	polkaReq := polkaRequest{}
+	err = json.Unmarshal(body, &polkaReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 400, "Invalid request body")
+		return
+	}
+
+	timestamp := ""
+	if cfg.polkaSecret != "" {
+		timestamp = r.Header.Get("X-Polka-Timestamp")
+	}
+	eventID := polkaEventID(polkaReq.ID, timestamp, body)
+
+	_, err = cfg.db.CreatePolkaEvent(r.Context(), database.CreatePolkaEventParams{
+		ID:      eventID,
+		Event:   polkaReq.Event,
+		Payload: body,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error recording polka event: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	// Claiming the event is what makes retries idempotent: of several
+	// concurrent deliveries only one gets it back, and none do once it has
+	// been handled.
+	event, err := cfg.db.ClaimPolkaEvent(r.Context(), database.ClaimPolkaEventParams{
+		ID:       eventID,
+		Statuses: []string{polkaEventReceived, polkaEventFailed},
+	})
+	if err == sql.ErrNoRows {
+		event, err = cfg.db.GetPolkaEvent(r.Context(), eventID)
+		if err != nil {
+			log.Printf("Error retrieving polka event: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		if event.Status == polkaEventProcessing {
+			respondWithError(w, 409, "Event is already being processed")
+			return
+		}
+		respondWithJSON(w, 204, User{})
+		return
+	} else if err != nil {
+		log.Printf("Error claiming polka event: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	_, applyErr, err := cfg.processPolkaEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Error updating polka event: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if applyErr == errPolkaUserNotFound {
+		respondWithError(w, 404, "User not found")
+		return
+	} else if applyErr != nil {
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 204, User{})
+}
+
+// authenticatePolka verifies the HMAC signature over the raw body when a
+// webhook secret is configured, and otherwise falls back to comparing the
+// shared ApiKey, which only dev allows.
+func (cfg *apiConfig) authenticatePolka(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	if cfg.polkaSecret != "" {
+		err := auth.VerifyWebhookSignature(
+			cfg.polkaSecret,
+			r.Header.Get("X-Polka-Timestamp"),
+			body,
+			r.Header.Get("X-Polka-Signature"),
+			time.Now(),
+			polkaSignatureTolerance,
+		)
+		if err != nil {
+			log.Printf("Invalid polka signature: %s", err)
+			respondWithError(w, 401, "Invalid signature")
+			return false
+		}
+		return true
+	}
+
+	polkaKey, err := auth.GetAPIKey(r.Header)
+	if err != nil || subtle.ConstantTimeCompare([]byte(polkaKey), []byte(cfg.polkaKey)) != 1 {
+		log.Printf("Invalid apiKey: %s", err)
+		respondWithError(w, 401, "Invalid key")
+		return false
+	}
+	return true
+}
+
+// polkaEventID picks the key deliveries of an event are deduplicated on.
+// Older Polka payloads carry no event ID, and their bodies repeat for events
+// that differ, such as a second upgrade or next month's renewal. Those are
+// keyed on the signed timestamp as well, which a redelivery of the same
+// request shares. Unsigned events without an ID are never deduplicated.
+func polkaEventID(id, timestamp string, body []byte) string {
+	if id != "" {
+		return id
+	}
+	if timestamp == "" {
+		return "unsigned:" + uuid.NewString()
+	}
+	sum := sha256.Sum256(append([]byte(timestamp+"."), body...))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// processPolkaEvent applies a stored event and records the outcome on it.
+// applyErr is why the event itself failed; err means the outcome could not
+// be recorded.
+func (cfg *apiConfig) processPolkaEvent(ctx context.Context, event database.PolkaEvent) (updated database.PolkaEvent, applyErr error, err error) {
+	status := polkaEventProcessed
+	applyErr = cfg.applyPolkaEvent(ctx, event)
+	if applyErr == errPolkaEventIgnored {
+		status, applyErr = polkaEventIgnored, nil
+	} else if applyErr != nil {
+		status = polkaEventFailed
+		log.Printf("Error processing polka event %s: %s", event.ID, applyErr)
+	}
+
+	lastError := sql.NullString{}
+	if applyErr != nil {
+		lastError = sql.NullString{String: applyErr.Error(), Valid: true}
+	}
+	updated, err = cfg.db.UpdatePolkaEventStatus(ctx, database.UpdatePolkaEventStatusParams{
+		Status:    status,
+		LastError: lastError,
+		ID:        event.ID,
+	})
+	return updated, applyErr, err
+}
+
+func (cfg *apiConfig) applyPolkaEvent(ctx context.Context, event database.PolkaEvent) error {
+	polkaReq := polkaRequest{}
+	err := json.Unmarshal(event.Payload, &polkaReq)
+	if err != nil {
+		return err
+	}
+
+	switch polkaReq.Event {
+	case polkaUserUpgraded, polkaUserDowngraded, polkaSubscriptionRenewed, polkaPaymentFailed:
+	default:
+		return errPolkaEventIgnored
+	}
+
+	userID, err := uuid.Parse(polkaReq.Data.UserID)
+	if err != nil {
+		return err
+	}
+	periodStart := nullTime(polkaReq.Data.PeriodStart)
+	periodEnd := nullTime(polkaReq.Data.PeriodEnd)
+
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	_, err = qtx.GetUser(ctx, userID)
+	if err == sql.ErrNoRows {
+		return errPolkaUserNotFound
+	} else if err != nil {
+		return err
+	}
+
+	switch polkaReq.Event {
+	case polkaUserUpgraded:
+		err = startSubscription(ctx, qtx, userID, periodStart, periodEnd)
+	case polkaSubscriptionRenewed:
+		_, err = qtx.RenewSubscription(ctx, database.RenewSubscriptionParams{
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			UserID:      userID,
+		})
+		// A renewal for a user we never saw upgrade starts them afresh.
+		if err == sql.ErrNoRows {
+			err = startSubscription(ctx, qtx, userID, periodStart, periodEnd)
+		} else if err == nil {
+			_, err = qtx.UpgradeUser(ctx, userID)
+		}
+	case polkaPaymentFailed:
+		// Red stays on until the paid period lapses, giving Polka the
+		// remainder of it to retry the payment.
+		_, err = qtx.SetSubscriptionStatus(ctx, database.SetSubscriptionStatusParams{
+			Status: subscriptionPastDue,
+			UserID: userID,
+		})
+		if err == sql.ErrNoRows {
+			return errPolkaEventIgnored
+		}
+	case polkaUserDowngraded:
+		_, err = qtx.SetSubscriptionStatus(ctx, database.SetSubscriptionStatusParams{
+			Status: subscriptionCanceled,
+			UserID: userID,
+		})
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+		if err == nil {
+			_, err = qtx.DowngradeUser(ctx, userID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func startSubscription(ctx context.Context, qtx *database.Queries, userID uuid.UUID, periodStart, periodEnd sql.NullTime) error {
+	_, err := qtx.StartSubscription(ctx, database.StartSubscriptionParams{
+		UserID:      userID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = qtx.UpgradeUser(ctx, userID)
+	return err
+}
+
+// nullTime converts an optional payload timestamp to UTC, which is what the
+// database's TIMESTAMP columns hold.
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func (cfg *apiConfig) handlerListPolkaEvents(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	limit := int32(defaultPageLimit)
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		n, err := strconv.Atoi(limitParam)
+		if err != nil || n < 1 {
+			respondWithError(w, 400, "limit must be a positive integer")
+			return
+		}
+		limit = int32(min(n, maxPageLimit))
+	}
+	status := sql.NullString{}
+	if statusParam := r.URL.Query().Get("status"); statusParam != "" {
+		status = sql.NullString{String: statusParam, Valid: true}
+	}
+
+	events, err := cfg.db.ListPolkaEvents(r.Context(), database.ListPolkaEventsParams{
+		Status: status,
+		Limit:  limit,
+	})
+	if err != nil {
+		log.Printf("Error retrieving polka events: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	eventResponses := make([]polkaEventResponse, 0, len(events))
+	for _, event := range events {
+		eventResponses = append(eventResponses, newPolkaEventResponse(event))
+	}
+	respondWithJSON(w, 200, eventResponses)
+}
+
+// handlerReplayPolkaEvent runs a stored event through processing again,
+// whatever its previous outcome.
+func (cfg *apiConfig) handlerReplayPolkaEvent(w http.ResponseWriter, r *http.Request) {
+	if !cfg.requireAdmin(w, r) {
+		return
+	}
+
+	// Replays may redo an event in any state except one being processed
+	// right now.
+	eventId := r.PathValue("eventID")
+	event, err := cfg.db.ClaimPolkaEvent(r.Context(), database.ClaimPolkaEventParams{
+		ID:       eventId,
+		Statuses: []string{polkaEventReceived, polkaEventFailed, polkaEventProcessed, polkaEventIgnored},
+	})
+	if err == sql.ErrNoRows {
+		_, err = cfg.db.GetPolkaEvent(r.Context(), eventId)
+		if err == sql.ErrNoRows {
+			respondWithError(w, 404, "The requested event was not found")
+			return
+		} else if err != nil {
+			log.Printf("Error retrieving polka event: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		respondWithError(w, 409, "Event is already being processed")
+		return
+	} else if err != nil {
+		log.Printf("Error claiming polka event: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	updated, _, err := cfg.processPolkaEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Error updating polka event: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, newPolkaEventResponse(updated))
+}
+
+func newPolkaEventResponse(event database.PolkaEvent) polkaEventResponse {
+	response := polkaEventResponse{
+		ID:        event.ID,
+		CreatedAt: event.CreatedAt,
+		UpdatedAt: event.UpdatedAt,
+		Event:     event.Event,
+		Payload:   event.Payload,
+		Status:    event.Status,
+		LastError: event.LastError.String,
+		Attempts:  event.Attempts,
+	}
+	if event.ProcessedAt.Valid {
+		response.ProcessedAt = &event.ProcessedAt.Time
+	}
+	return response
+}
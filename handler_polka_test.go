@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"internal/auth"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sendPolkaEvent delivers an event without an ID, signed as sent at sent.
+func sendPolkaEvent(t *testing.T, cfg *apiConfig, event string, userID uuid.UUID, sent time.Time) {
+	t.Helper()
+	body := fmt.Sprintf(`{"event":%q,"data":{"user_id":%q}}`, event, userID)
+	timestamp := strconv.FormatInt(sent.Unix(), 10)
+	req := httptest.NewRequest("POST", "/api/polka/webhooks", strings.NewReader(body))
+	req.Header.Set("X-Polka-Timestamp", timestamp)
+	req.Header.Set("X-Polka-Signature", auth.SignWebhook(cfg.polkaSecret, timestamp, []byte(body)))
+	rec := httptest.NewRecorder()
+	cfg.handlerPolkaWebhook(rec, req)
+	if rec.Code != 204 {
+		t.Fatalf("%s: status = %d, body %s", event, rec.Code, rec.Body)
+	}
+}
+
+func TestPolkaWebhookAppliesRepeatedUpgrades(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.polkaSecret = "test-secret"
+	user := createTestUser(t, cfg, "password")
+	now := time.Now()
+
+	steps := []struct {
+		event string
+		red   bool
+	}{
+		{polkaUserUpgraded, true},
+		{polkaUserDowngraded, false},
+		{polkaUserUpgraded, true},
+	}
+	for i, step := range steps {
+		sendPolkaEvent(t, cfg, step.event, user.ID, now.Add(time.Duration(i)*time.Second))
+		got, err := cfg.db.GetUser(context.Background(), user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.IsChirpyRed != step.red {
+			t.Fatalf("after %s #%d: IsChirpyRed = %v, want %v", step.event, i, got.IsChirpyRed, step.red)
+		}
+	}
+}
+
+func TestPolkaWebhookAppliesEachRenewal(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.polkaSecret = "test-secret"
+	user := createTestUser(t, cfg, "password")
+	ctx := context.Background()
+	now := time.Now()
+
+	sendPolkaEvent(t, cfg, polkaUserUpgraded, user.ID, now)
+	sub, err := cfg.db.GetSubscription(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		sent := now.Add(time.Duration(i) * time.Second)
+		sendPolkaEvent(t, cfg, polkaSubscriptionRenewed, user.ID, sent)
+		renewed, err := cfg.db.GetSubscription(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !renewed.CurrentPeriodEnd.After(sub.CurrentPeriodEnd) {
+			t.Fatalf("renewal %d: period ends %v, was %v", i, renewed.CurrentPeriodEnd, sub.CurrentPeriodEnd)
+		}
+		sub = renewed
+
+		// A redelivery of the same request is not applied again.
+		sendPolkaEvent(t, cfg, polkaSubscriptionRenewed, user.ID, sent)
+		redelivered, err := cfg.db.GetSubscription(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !redelivered.CurrentPeriodEnd.Equal(sub.CurrentPeriodEnd) {
+			t.Fatalf("redelivered renewal %d: period ends %v, want %v", i, redelivered.CurrentPeriodEnd, sub.CurrentPeriodEnd)
+		}
+	}
+}
+
+func TestPolkaEventID(t *testing.T) {
+	body := []byte(`{"event":"user.upgraded","data":{"user_id":"x"}}`)
+
+	if got := polkaEventID("evt_1", "100", body); got != "evt_1" {
+		t.Errorf("polkaEventID with an ID = %q", got)
+	}
+	if polkaEventID("", "100", body) != polkaEventID("", "100", body) {
+		t.Error("a redelivered signed event got a new key")
+	}
+	if polkaEventID("", "100", body) == polkaEventID("", "200", body) {
+		t.Error("identical bodies signed at different times share a key")
+	}
+	if polkaEventID("", "", body) == polkaEventID("", "", body) {
+		t.Error("unsigned events without an ID share a key")
+	}
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -11,74 +13,107 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type TokenType string
 
 const (
 	TokenTypeAccess TokenType = "chirpy-access"
+	// TokenTypeMFA is issued after a correct password for an account with
+	// two-factor authentication and only grants the right to submit a code.
+	TokenTypeMFA TokenType = "chirpy-mfa"
 )
 
-func HashPassword(password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hash), nil
+func MakeJWT(
+	userID uuid.UUID,
+	keys *KeySet,
+	expiresIn time.Duration,
+) (string, error) {
+	return makeJWT(userID, keys, TokenTypeAccess, expiresIn)
 }
 
-func CheckPasswordHash(password string, hash string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err
+func ValidateJWT(tokenString string, keys *KeySet) (uuid.UUID, error) {
+	userID, _, err := validateJWT(tokenString, keys, TokenTypeAccess)
+	return userID, err
 }
 
-func MakeJWT(
+// ValidateJWTWithExpiry also returns when the access token expires, for
+// long-lived connections that must stop trusting it at that point.
+func ValidateJWTWithExpiry(tokenString string, keys *KeySet) (uuid.UUID, time.Time, error) {
+	userID, expiresAt, err := validateJWT(tokenString, keys, TokenTypeAccess)
+	if err == nil && expiresAt.IsZero() {
+		return uuid.Nil, time.Time{}, errors.New("token has no expiry")
+	}
+	return userID, expiresAt, err
+}
+
+func MakeMFAChallengeJWT(
 	userID uuid.UUID,
-	tokenSecret string,
+	keys *KeySet,
 	expiresIn time.Duration,
 ) (string, error) {
-	signingKey := []byte(tokenSecret)
+	return makeJWT(userID, keys, TokenTypeMFA, expiresIn)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Issuer:    string(TokenTypeAccess),
+func ValidateMFAChallengeJWT(tokenString string, keys *KeySet) (uuid.UUID, error) {
+	userID, _, err := validateJWT(tokenString, keys, TokenTypeMFA)
+	return userID, err
+}
+
+func makeJWT(
+	userID uuid.UUID,
+	keys *KeySet,
+	tokenType TokenType,
+	expiresIn time.Duration,
+) (string, error) {
+	return keys.sign(jwt.RegisteredClaims{
+		Issuer:    string(tokenType),
 		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(expiresIn)),
 		Subject:   userID.String(),
 	})
-
-	return token.SignedString(signingKey)
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+func validateJWT(tokenString string, keys *KeySet, tokenType TokenType) (uuid.UUID, time.Time, error) {
 	claimsStruct := jwt.RegisteredClaims{}
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&claimsStruct,
-		func(token *jwt.Token) (interface{}, error) { return []byte(tokenSecret), nil },
+		keys.keyfunc,
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodEdDSA.Alg(),
+			jwt.SigningMethodRS256.Alg(),
+		}),
 	)
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, time.Time{}, err
 	}
 
 	userIDString, err := token.Claims.GetSubject()
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, time.Time{}, err
 	}
 
 	issuer, err := token.Claims.GetIssuer()
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, time.Time{}, err
 	}
-	if issuer != string(TokenTypeAccess) {
-		return uuid.Nil, errors.New("invalid issuer")
+	if issuer != string(tokenType) {
+		return uuid.Nil, time.Time{}, errors.New("invalid issuer")
 	}
 
 	id, err := uuid.Parse(userIDString)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid user ID: %w", err)
+		return uuid.Nil, time.Time{}, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	// Every token we issue expires; the zero time stands for one that does
+	// not.
+	expiresAt, err := token.Claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return id, time.Time{}, nil
 	}
-	return id, nil
+	return id, expiresAt.Time, nil
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
@@ -97,6 +132,33 @@ func MakeRefreshToken() (string, error) {
 	return encoded, nil
 }
 
+// MakeOneTimeToken returns a random token for single-use links sent by
+// email, such as password resets.
+func MakeOneTimeToken() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func HashOneTimeToken(token, pepper string) string {
+	return pepperedDigest(token, pepper)
+}
+
+// HashRefreshToken returns the digest under which a refresh token is stored.
+// The pepper is kept out of the database so a dump alone cannot be used to
+// check guesses against stored tokens.
+func HashRefreshToken(token, pepper string) string {
+	return pepperedDigest(token, pepper)
+}
+
+func pepperedDigest(value, pepper string) string {
+	mac := hmac.New(sha256.New, []byte(pepper))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func GetAPIKey(headers http.Header) (string, error) {
 	auth := headers.Get("Authorization")
 	if len(auth) == 0 {
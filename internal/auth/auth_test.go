@@ -1,7 +1,10 @@
 package auth
 
 import (
+	"strings"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestCheckPasswordHash(t *testing.T) {
@@ -58,3 +61,84 @@ func TestCheckPasswordHash(t *testing.T) {
 		})
 	}
 }
+
+func TestHashRefreshToken(t *testing.T) {
+	token, _ := MakeRefreshToken()
+	hash := HashRefreshToken(token, "pepper")
+
+	if hash == token {
+		t.Fatalf("HashRefreshToken() returned the token unchanged")
+	}
+	if HashRefreshToken(token, "pepper") != hash {
+		t.Errorf("HashRefreshToken() is not deterministic")
+	}
+	if HashRefreshToken(token, "other pepper") == hash {
+		t.Errorf("HashRefreshToken() ignored the pepper")
+	}
+}
+
+func TestCheckPasswordHashLegacyBcrypt(t *testing.T) {
+	password := "correctPassword123!"
+	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+
+	if err := CheckPasswordHash(password, string(hash)); err != nil {
+		t.Errorf("CheckPasswordHash() with bcrypt hash error = %v", err)
+	}
+	if !NeedsRehash(string(hash)) {
+		t.Errorf("NeedsRehash() = false for a bcrypt hash")
+	}
+}
+
+func TestCheckPasswordHashLongPassword(t *testing.T) {
+	// bcrypt ignores everything past 72 bytes; argon2id must not.
+	prefix := strings.Repeat("a", 72)
+	hash, _ := HashPassword(prefix + "1")
+
+	if err := CheckPasswordHash(prefix+"2", hash); err == nil {
+		t.Errorf("CheckPasswordHash() accepted a password differing after 72 bytes")
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	defer SetArgon2Params(DefaultArgon2Params)
+
+	hash, _ := HashPassword("password")
+	if NeedsRehash(hash) {
+		t.Errorf("NeedsRehash() = true for a hash with current parameters")
+	}
+
+	stronger := DefaultArgon2Params
+	stronger.Iterations++
+	SetArgon2Params(stronger)
+	if !NeedsRehash(hash) {
+		t.Errorf("NeedsRehash() = false after parameters changed")
+	}
+	if err := CheckPasswordHash("password", hash); err != nil {
+		t.Errorf("CheckPasswordHash() with older parameters error = %v", err)
+	}
+}
+
+func TestCheckPasswordHashRejectsOutOfRangeParams(t *testing.T) {
+	hash, _ := HashPassword("password")
+	parts := strings.Split(hash, "$")
+
+	for _, params := range []string{
+		"m=4194304,t=3,p=4",
+		"m=65536,t=1000000,p=4",
+		"m=65536,t=3,p=255",
+		"m=0,t=3,p=4",
+		"m=65536,t=0,p=4",
+		"m=65536,t=3,p=0",
+	} {
+		t.Run(params, func(t *testing.T) {
+			parts[3] = params
+			tampered := strings.Join(parts, "$")
+			if err := CheckPasswordHash("password", tampered); err == nil {
+				t.Errorf("CheckPasswordHash() accepted a hash with %s", params)
+			}
+			if !NeedsRehash(tampered) {
+				t.Errorf("NeedsRehash() = false for a hash with %s", params)
+			}
+		})
+	}
+}
@@ -7,3 +7,5 @@ require (
 	github.com/google/uuid v1.6.0
 	golang.org/x/crypto v0.36.0
 )
+
+require golang.org/x/sys v0.31.0 // indirect
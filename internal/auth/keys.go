@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWK is the public half of a verification key as published at
+// /.well-known/jwks.json (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// KeySet signs access tokens with a single current key and accepts tokens
+// signed by any of its verification keys, so a new signing key can be rolled
+// out while tokens issued under the previous one are still honoured.
+type KeySet struct {
+	signingKID string
+	signer     crypto.Signer
+	keys       []verificationKey
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{}
+}
+
+// LoadKeySet reads a PEM encoded Ed25519 or RSA private key used for signing,
+// plus any number of PEM files holding keys that are only trusted for
+// verification (typically the previous signing keys).
+func LoadKeySet(signingKeyFile string, verificationKeyFiles []string) (*KeySet, error) {
+	ks := NewKeySet()
+
+	key, err := readPEMKey(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: signing key must be a private key", signingKeyFile)
+	}
+	if err := ks.SetSigningKey(signer); err != nil {
+		return nil, fmt.Errorf("%s: %w", signingKeyFile, err)
+	}
+
+	for _, file := range verificationKeyFiles {
+		key, err := readPEMKey(file)
+		if err != nil {
+			return nil, err
+		}
+		if signer, ok := key.(crypto.Signer); ok {
+			key = signer.Public()
+		}
+		if _, err := ks.AddVerificationKey(key); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return ks, nil
+}
+
+// GenerateKeySet returns a key set holding a fresh Ed25519 key. Tokens signed
+// by it stop validating once the process exits.
+func GenerateKeySet() (*KeySet, error) {
+	_, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	ks := NewKeySet()
+	if err := ks.SetSigningKey(private); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// SetSigningKey makes key the one used for new tokens. Its public half is
+// also trusted for verification.
+func (ks *KeySet) SetSigningKey(key crypto.Signer) error {
+	kid, err := ks.AddVerificationKey(key.Public())
+	if err != nil {
+		return err
+	}
+	ks.signingKID = kid
+	ks.signer = key
+	return nil
+}
+
+// AddVerificationKey trusts tokens signed by the private half of key and
+// returns the key ID it will be published under.
+func (ks *KeySet) AddVerificationKey(key crypto.PublicKey) (string, error) {
+	method, err := signingMethodFor(key)
+	if err != nil {
+		return "", err
+	}
+	kid, err := thumbprint(key)
+	if err != nil {
+		return "", err
+	}
+	for _, existing := range ks.keys {
+		if existing.kid == kid {
+			return kid, nil
+		}
+	}
+	ks.keys = append(ks.keys, verificationKey{
+		kid:    kid,
+		method: method,
+		public: key,
+	})
+	return kid, nil
+}
+
+func (ks *KeySet) lookup(kid string) (verificationKey, bool) {
+	for _, key := range ks.keys {
+		if key.kid == kid {
+			return key, true
+		}
+	}
+	return verificationKey{}, false
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	if ks.signer == nil {
+		return "", errors.New("no signing key configured")
+	}
+	key, _ := ks.lookup(ks.signingKID)
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = ks.signingKID
+	return token.SignedString(ks.signer)
+}
+
+func (ks *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("token has no key id")
+	}
+	key, ok := ks.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("key %q does not sign with %s", kid, token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// JWKS returns every verification key in the set in publishable form.
+func (ks *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := JWK{
+			Use: "sig",
+			Alg: key.method.Alg(),
+			Kid: key.kid,
+		}
+		switch public := key.public.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func signingMethodFor(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", key)
+}
+
+// thumbprint derives a key ID from the key itself (RFC 7638) so that no
+// separate naming scheme has to be kept in sync across services.
+func thumbprint(key crypto.PublicKey) (string, error) {
+	var canonical string
+	switch public := key.(type) {
+	case ed25519.PublicKey:
+		canonical = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`,
+			base64.RawURLEncoding.EncodeToString(public))
+	case *rsa.PublicKey:
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`,
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			base64.RawURLEncoding.EncodeToString(public.N.Bytes()))
+	default:
+		return "", fmt.Errorf("unsupported key type %T", key)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func readPEMKey(file string) (interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", file)
+	}
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("%s: unsupported PEM block %q", file, block.Type)
+}
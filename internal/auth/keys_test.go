@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateJWT(t *testing.T) {
+	userID := uuid.New()
+	keys, _ := GenerateKeySet()
+	otherKeys, _ := GenerateKeySet()
+
+	validToken, _ := MakeJWT(userID, keys, time.Hour)
+	expiredToken, _ := MakeJWT(userID, keys, -time.Hour)
+
+	tests := []struct {
+		name        string
+		tokenString string
+		keys        *KeySet
+		wantUserID  uuid.UUID
+		wantErr     bool
+	}{
+		{
+			name:        "Valid token",
+			tokenString: validToken,
+			keys:        keys,
+			wantUserID:  userID,
+			wantErr:     false,
+		},
+		{
+			name:        "Expired token",
+			tokenString: expiredToken,
+			keys:        keys,
+			wantUserID:  uuid.Nil,
+			wantErr:     true,
+		},
+		{
+			name:        "Unknown signing key",
+			tokenString: validToken,
+			keys:        otherKeys,
+			wantUserID:  uuid.Nil,
+			wantErr:     true,
+		},
+		{
+			name:        "Invalid token",
+			tokenString: "invalid.token.string",
+			keys:        keys,
+			wantUserID:  uuid.Nil,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUserID, err := ValidateJWT(tt.tokenString, tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateJWT() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotUserID != tt.wantUserID {
+				t.Errorf("ValidateJWT() gotUserID = %v, want %v", gotUserID, tt.wantUserID)
+			}
+		})
+	}
+}
+
+func TestValidateJWTWithExpiry(t *testing.T) {
+	userID := uuid.New()
+	keys, _ := GenerateKeySet()
+	token, _ := MakeJWT(userID, keys, time.Hour)
+
+	gotUserID, expiresAt, err := ValidateJWTWithExpiry(token, keys)
+	if err != nil {
+		t.Fatalf("ValidateJWTWithExpiry() error = %v", err)
+	}
+	if gotUserID != userID {
+		t.Errorf("ValidateJWTWithExpiry() gotUserID = %v, want %v", gotUserID, userID)
+	}
+	if until := time.Until(expiresAt); until < 59*time.Minute || until > time.Hour {
+		t.Errorf("ValidateJWTWithExpiry() expiresAt = %v, want an hour from now", expiresAt)
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	userID := uuid.New()
+	_, oldKey, _ := ed25519.GenerateKey(nil)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	keys := NewKeySet()
+	keys.SetSigningKey(oldKey)
+	oldToken, _ := MakeJWT(userID, keys, time.Hour)
+
+	keys.SetSigningKey(newKey)
+	newToken, _ := MakeJWT(userID, keys, time.Hour)
+
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := ValidateJWT(token, keys); err != nil {
+			t.Errorf("ValidateJWT() after rotation error = %v", err)
+		}
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS() returned %d keys, want 2", len(jwks.Keys))
+	}
+	if jwks.Keys[0].Kty != "OKP" || jwks.Keys[1].Kty != "RSA" {
+		t.Errorf("JWKS() key types = %s, %s", jwks.Keys[0].Kty, jwks.Keys[1].Kty)
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+	_, signingKey, _ := ed25519.GenerateKey(nil)
+	oldPublic, oldPrivate, _ := ed25519.GenerateKey(nil)
+
+	signingFile := writePEM(t, dir, "signing.pem", "PRIVATE KEY", mustMarshal(x509.MarshalPKCS8PrivateKey(signingKey)))
+	oldFile := writePEM(t, dir, "old.pem", "PUBLIC KEY", mustMarshal(x509.MarshalPKIXPublicKey(oldPublic)))
+
+	keys, err := LoadKeySet(signingFile, []string{oldFile})
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+
+	oldKeys := NewKeySet()
+	oldKeys.SetSigningKey(oldPrivate)
+	oldToken, _ := MakeJWT(uuid.New(), oldKeys, time.Hour)
+	if _, err := ValidateJWT(oldToken, keys); err != nil {
+		t.Errorf("ValidateJWT() with verification-only key error = %v", err)
+	}
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func mustMarshal(der []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return der
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Argon2Params are the argon2id cost parameters used for new password
+// hashes. They are encoded into every hash, so changing them only affects
+// hashes created (or upgraded) afterwards.
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follow the second recommended option in RFC 9106.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// MaxArgon2Params bounds the parameters accepted from configuration and from
+// stored hashes, so a tampered hash cannot make every login attempt against
+// its account allocate gigabytes.
+var MaxArgon2Params = Argon2Params{
+	Memory:      256 * 1024,
+	Iterations:  16,
+	Parallelism: 16,
+	SaltLength:  64,
+	KeyLength:   64,
+}
+
+var passwordParams = DefaultArgon2Params
+
+var ErrMismatchedPassword = errors.New("password does not match hash")
+
+// Validate checks that every parameter is positive and within
+// MaxArgon2Params.
+func (p Argon2Params) Validate() error {
+	for _, param := range []struct {
+		name       string
+		value, max uint32
+	}{
+		{"memory", p.Memory, MaxArgon2Params.Memory},
+		{"iterations", p.Iterations, MaxArgon2Params.Iterations},
+		{"parallelism", uint32(p.Parallelism), uint32(MaxArgon2Params.Parallelism)},
+		{"salt length", p.SaltLength, MaxArgon2Params.SaltLength},
+		{"key length", p.KeyLength, MaxArgon2Params.KeyLength},
+	} {
+		if param.value == 0 || param.value > param.max {
+			return fmt.Errorf("argon2id %s %d is outside 1 to %d", param.name, param.value, param.max)
+		}
+	}
+	return nil
+}
+
+// SetArgon2Params changes the parameters HashPassword uses for new hashes.
+func SetArgon2Params(params Argon2Params) {
+	passwordParams = params
+}
+
+// HashPassword hashes password with argon2id in the PHC string format:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+func HashPassword(password string) (string, error) {
+	return hashArgon2id(password, passwordParams)
+}
+
+func hashArgon2id(password string, params Argon2Params) (string, error) {
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// CheckPasswordHash accepts both argon2id hashes and the bcrypt hashes
+// created before argon2id was introduced.
+func CheckPasswordHash(password string, hash string) error {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	}
+
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return ErrMismatchedPassword
+	}
+	return nil
+}
+
+// NeedsRehash reports whether hash was made with an older algorithm or
+// different parameters than HashPassword currently uses, so it should be
+// replaced the next time the plaintext password is available.
+func NeedsRehash(hash string) bool {
+	params, salt, _, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return params.Memory != passwordParams.Memory ||
+		params.Iterations != passwordParams.Iterations ||
+		params.Parallelism != passwordParams.Parallelism ||
+		params.KeyLength != passwordParams.KeyLength ||
+		uint32(len(salt)) != passwordParams.SaltLength
+}
+
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2Params{}, nil, nil, errors.New("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	params := Argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, fmt.Errorf("invalid argon2id key: %w", err)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	if err := params.Validate(); err != nil {
+		return Argon2Params{}, nil, nil, err
+	}
+	return params, salt, key, nil
+}
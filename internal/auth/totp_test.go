@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	// Test vectors from RFC 6238 appendix B, truncated to six digits.
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		name     string
+		code     string
+		now      time.Time
+		wantStep int64
+		wantErr  bool
+	}{
+		{
+			name:     "RFC vector at 59",
+			code:     "287082",
+			now:      time.Unix(59, 0),
+			wantStep: 1,
+			wantErr:  false,
+		},
+		{
+			name:     "RFC vector at 1111111109",
+			code:     "081804",
+			now:      time.Unix(1111111109, 0),
+			wantStep: 37037036,
+			wantErr:  false,
+		},
+		{
+			name:     "Previous period is accepted",
+			code:     "081804",
+			now:      time.Unix(1111111109+30, 0),
+			wantStep: 37037036,
+			wantErr:  false,
+		},
+		{
+			name:    "Code too old",
+			code:    "081804",
+			now:     time.Unix(1111111109+90, 0),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong code",
+			code:    "123456",
+			now:     time.Unix(59, 0),
+			wantErr: true,
+		},
+		{
+			name:    "Wrong length",
+			code:    "28708",
+			now:     time.Unix(59, 0),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, err := ValidateTOTP(secret, tt.code, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTOTP() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && step != tt.wantStep {
+				t.Errorf("ValidateTOTP() step = %d, want %d", step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	codes, _ := MakeRecoveryCodes()
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("MakeRecoveryCodes() returned %d codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	code := codes[0]
+	typed := " " + code[:5] + code[6:] + " "
+	if HashRecoveryCode(typed, "pepper") != HashRecoveryCode(code, "pepper") {
+		t.Errorf("HashRecoveryCode() is sensitive to formatting of %q", code)
+	}
+}
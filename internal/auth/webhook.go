@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleWebhook     = errors.New("webhook timestamp outside tolerance")
+)
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>", the value
+// expected in a webhook signature header.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks signature against the raw request body and
+// rejects timestamps further than tolerance from now, so a captured request
+// cannot be replayed later.
+func VerifyWebhookSignature(secret, timestamp string, body []byte, signature string, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrStaleWebhook
+	}
+	sent := time.Unix(unix, 0)
+	if sent.Before(now.Add(-tolerance)) || sent.After(now.Add(tolerance)) {
+		return ErrStaleWebhook
+	}
+
+	expected, _ := hex.DecodeString(SignWebhook(secret, timestamp, body))
+	got, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, got) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	secret := "whsec"
+	body := []byte(`{"event":"user.upgraded","data":{"user_id":"3311741c-680c-4546-99f3-fc9efac2036c"}}`)
+	now := time.Unix(1700000000, 0)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := SignWebhook(secret, timestamp, body)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		body      []byte
+		signature string
+		now       time.Time
+		wantErr   error
+	}{
+		{
+			name:      "Valid signature",
+			timestamp: timestamp,
+			body:      body,
+			signature: signature,
+			now:       now,
+			wantErr:   nil,
+		},
+		{
+			name:      "Tampered body",
+			timestamp: timestamp,
+			body:      append([]byte(nil), append(body, ' ')...),
+			signature: signature,
+			now:       now,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "Replayed outside tolerance",
+			timestamp: timestamp,
+			body:      body,
+			signature: signature,
+			now:       now.Add(10 * time.Minute),
+			wantErr:   ErrStaleWebhook,
+		},
+		{
+			name:      "Timestamp changed",
+			timestamp: strconv.FormatInt(now.Unix()+1, 10),
+			body:      body,
+			signature: signature,
+			now:       now,
+			wantErr:   ErrInvalidSignature,
+		},
+		{
+			name:      "Malformed signature",
+			timestamp: timestamp,
+			body:      body,
+			signature: "not-hex",
+			now:       now,
+			wantErr:   ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(secret, tt.timestamp, tt.body, tt.signature, tt.now, 5*time.Minute)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyWebhookSignature() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
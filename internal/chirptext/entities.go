@@ -0,0 +1,80 @@
+package chirptext
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxTagLength    = 100
+	maxHandleLength = 30
+)
+
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,30}$`)
+
+// ValidHandle reports whether handle can be used as a user's @handle.
+func ValidHandle(handle string) bool {
+	return handlePattern.MatchString(handle)
+}
+
+// ExtractTags returns the distinct #hashtags in body, lower cased, in the
+// order they first appear. A tag is a run of letters, marks, digits and
+// underscores, and only starts a tag when # is not preceded by one of
+// those, so "C#" and URL fragments are not tags.
+func ExtractTags(body string) []string {
+	return extractEntities(body, '#', maxTagLength, func(r rune) bool {
+		return isWordRune(r) || r == '_'
+	})
+}
+
+// ExtractMentions returns the distinct @handles mentioned in body, lower
+// cased, in the order they first appear. Email addresses are not mentions.
+func ExtractMentions(body string) []string {
+	return extractEntities(body, '@', maxHandleLength, func(r rune) bool {
+		return r < utf8.RuneSelf && (r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+}
+
+func extractEntities(body string, sigil rune, maxLength int, inEntity func(rune) bool) []string {
+	// Hash signs and at signs inside links are part of the link.
+	body = urlPattern.ReplaceAllString(body, " ")
+
+	var entities []string
+	seen := map[string]bool{}
+	prev := ' '
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRuneInString(body[i:])
+		i += size
+		if r != sigil || inEntity(prev) || prev == sigil {
+			prev = r
+			continue
+		}
+		prev = r
+
+		end := i
+		for end < len(body) {
+			r, size := utf8.DecodeRuneInString(body[end:])
+			if !inEntity(r) {
+				break
+			}
+			end += size
+		}
+		entity := strings.ToLower(body[i:end])
+		if end > i {
+			prev, _ = utf8.DecodeLastRuneInString(body[i:end])
+		}
+		i = end
+		// "@zoë" is not a mention of zo.
+		if next, _ := utf8.DecodeRuneInString(body[end:]); end < len(body) && isWordRune(next) {
+			continue
+		}
+		if entity == "" || utf8.RuneCountInString(entity) > maxLength || seen[entity] {
+			continue
+		}
+		seen[entity] = true
+		entities = append(entities, entity)
+	}
+	return entities
+}
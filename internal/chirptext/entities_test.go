@@ -0,0 +1,72 @@
+package chirptext
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractTags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "None", body: "no tags here", want: nil},
+		{name: "Single", body: "learning #golang today", want: []string{"golang"}},
+		{name: "Case and repeats", body: "#Go #go #GO", want: []string{"go"}},
+		{name: "Punctuation", body: "(#boot_dev), #chirpy!", want: []string{"boot_dev", "chirpy"}},
+		{name: "Adjacent tags", body: "#one#two", want: []string{"one"}},
+		{name: "Not after a word", body: "C# and F#", want: nil},
+		{name: "Unicode", body: "#café #東京", want: []string{"café", "東京"}},
+		{name: "Bare hash", body: "# heading", want: nil},
+		{name: "URL fragment", body: "see https://example.com/page#section", want: nil},
+		{name: "Too long", body: "#" + strings.Repeat("a", 101), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractTags(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractTags(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "Single", body: "hi @Alice", want: []string{"alice"}},
+		{name: "Several", body: "@bob, @carol_1: and @bob again", want: []string{"bob", "carol_1"}},
+		{name: "Email", body: "mail me at bob@example.com", want: nil},
+		{name: "Non-ASCII handle", body: "@zoë and @zoe", want: []string{"zoe"}},
+		{name: "Bare at", body: "meet @ noon", want: nil},
+		{name: "Too long", body: "@" + strings.Repeat("a", 31), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractMentions(tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractMentions(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidHandle(t *testing.T) {
+	for handle, want := range map[string]bool{
+		"alice":                 true,
+		"Bob_99":                true,
+		"":                      false,
+		"has space":             false,
+		"zoë":                   false,
+		strings.Repeat("a", 31): false,
+	} {
+		if got := ValidHandle(handle); got != want {
+			t.Errorf("ValidHandle(%q) = %v, want %v", handle, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package chirptext
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Action is what a Filter does with a chirp containing a listed word.
+type Action string
+
+const (
+	// ActionMask replaces the word with Mask.
+	ActionMask Action = "mask"
+	// ActionReject refuses the whole chirp.
+	ActionReject Action = "reject"
+	// ActionFlag lets the chirp through but marks it for moderator review.
+	ActionFlag Action = "flag"
+)
+
+// Mask is what masked words are replaced with.
+const Mask = "****"
+
+// Rule pairs a word with the action to take on it. Words match whole words
+// only, ignoring case.
+type Rule struct {
+	Word   string `json:"word"`
+	Action Action `json:"action"`
+}
+
+// FilterConfig is the file format read by LoadFilter.
+type FilterConfig struct {
+	Rules []Rule `json:"rules"`
+}
+
+// DefaultRules are used when no filter file is configured.
+var DefaultRules = []Rule{
+	{Word: "kerfuffle", Action: ActionMask},
+	{Word: "sharbert", Action: ActionMask},
+	{Word: "fornax", Action: ActionMask},
+}
+
+// Filter checks chirp bodies against a word list. It is immutable once
+// built, so a reload swaps in a new Filter rather than changing this one.
+type Filter struct {
+	actions map[string]Action
+}
+
+// FilterResult is the outcome of running a body through a Filter.
+type FilterResult struct {
+	// Body has every masked word replaced.
+	Body     string
+	Rejected bool
+	Flagged  bool
+	// Matches lists the distinct listed words found, lower cased, in the
+	// order they first appear.
+	Matches []string
+}
+
+// NewFilter builds a Filter from rules, rejecting unknown actions and words
+// that could never match a single word.
+func NewFilter(rules []Rule) (*Filter, error) {
+	actions := make(map[string]Action, len(rules))
+	for _, rule := range rules {
+		word := strings.ToLower(Normalize(strings.TrimSpace(rule.Word)))
+		if word == "" || strings.IndexFunc(word, func(r rune) bool { return !isWordRune(r) }) >= 0 {
+			return nil, fmt.Errorf("invalid filter word %q", rule.Word)
+		}
+		switch rule.Action {
+		case ActionMask, ActionReject, ActionFlag:
+		default:
+			return nil, fmt.Errorf("invalid action %q for filter word %q", rule.Action, rule.Word)
+		}
+		actions[word] = rule.Action
+	}
+	return &Filter{actions: actions}, nil
+}
+
+// LoadFilter reads a FilterConfig from a JSON file.
+func LoadFilter(path string) (*Filter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := FilterConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return NewFilter(config.Rules)
+}
+
+// Apply runs body through the filter. Words are the runs of letters, marks
+// and digits in body, so punctuation next to a word does not hide it. Links
+// are left alone, since masking part of one would break it.
+func (f *Filter) Apply(body string) FilterResult {
+	result := FilterResult{}
+	seen := map[string]bool{}
+	var out strings.Builder
+	out.Grow(len(body))
+
+	links := urlPattern.FindAllStringIndex(body, -1)
+	for i := 0; i < len(body); {
+		if len(links) > 0 && i == links[0][0] {
+			out.WriteString(body[i:links[0][1]])
+			i = links[0][1]
+			links = links[1:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(body[i:])
+		if !isWordRune(r) {
+			out.WriteString(body[i : i+size])
+			i += size
+			continue
+		}
+
+		end := i + size
+		for end < len(body) && (len(links) == 0 || end < links[0][0]) {
+			r, size := utf8.DecodeRuneInString(body[end:])
+			if !isWordRune(r) {
+				break
+			}
+			end += size
+		}
+		word := body[i:end]
+		i = end
+
+		lower := strings.ToLower(word)
+		action, ok := f.actions[lower]
+		if !ok {
+			out.WriteString(word)
+			continue
+		}
+		if !seen[lower] {
+			seen[lower] = true
+			result.Matches = append(result.Matches, lower)
+		}
+		switch action {
+		case ActionMask:
+			out.WriteString(Mask)
+			continue
+		case ActionReject:
+			result.Rejected = true
+		case ActionFlag:
+			result.Flagged = true
+		}
+		out.WriteString(word)
+	}
+
+	result.Body = out.String()
+	return result
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
+
+// Len is the number of words the filter lists.
+func (f *Filter) Len() int {
+	return len(f.actions)
+}
@@ -0,0 +1,156 @@
+package chirptext
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFilterApply(t *testing.T) {
+	filter, err := NewFilter([]Rule{
+		{Word: "kerfuffle", Action: ActionMask},
+		{Word: "Sharbert", Action: ActionMask},
+		{Word: "fornax", Action: ActionReject},
+		{Word: "spoiler", Action: ActionFlag},
+		{Word: "café", Action: ActionMask},
+	})
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		body         string
+		wantBody     string
+		wantRejected bool
+		wantFlagged  bool
+		wantMatches  []string
+	}{
+		{
+			name:     "Clean",
+			body:     "I had something interesting for breakfast",
+			wantBody: "I had something interesting for breakfast",
+		},
+		{
+			name:        "Masked",
+			body:        "This is a kerfuffle opinion I need to share with the world",
+			wantBody:    "This is a **** opinion I need to share with the world",
+			wantMatches: []string{"kerfuffle"},
+		},
+		{
+			name:        "Punctuation",
+			body:        "What a Kerfuffle! (sharbert.)",
+			wantBody:    "What a ****! (****.)",
+			wantMatches: []string{"kerfuffle", "sharbert"},
+		},
+		{
+			name:        "Case and repeats",
+			body:        "KERFUFFLE kerfuffle",
+			wantBody:    "**** ****",
+			wantMatches: []string{"kerfuffle"},
+		},
+		{
+			name:     "Word boundaries",
+			body:     "kerfuffles and subkerfuffle are fine",
+			wantBody: "kerfuffles and subkerfuffle are fine",
+		},
+		{
+			name:        "Whitespace",
+			body:        "tabs\tkerfuffle\nnewlines",
+			wantBody:    "tabs\t****\nnewlines",
+			wantMatches: []string{"kerfuffle"},
+		},
+		{
+			name:        "Non-ASCII word",
+			body:        "Meet me at the café, ok?",
+			wantBody:    "Meet me at the ****, ok?",
+			wantMatches: []string{"café"},
+		},
+		{
+			name:         "Rejected",
+			body:         "fornax, again",
+			wantBody:     "fornax, again",
+			wantRejected: true,
+			wantMatches:  []string{"fornax"},
+		},
+		{
+			name:        "Flagged",
+			body:        "Spoiler: the butler did it",
+			wantBody:    "Spoiler: the butler did it",
+			wantFlagged: true,
+			wantMatches: []string{"spoiler"},
+		},
+		{
+			name:     "Link path",
+			body:     "see https://example.com/kerfuffle for more",
+			wantBody: "see https://example.com/kerfuffle for more",
+		},
+		{
+			name:     "Link query",
+			body:     "http://example.com/?q=Sharbert&x=fornax",
+			wantBody: "http://example.com/?q=Sharbert&x=fornax",
+		},
+		{
+			name:     "Word run into a link",
+			body:     "seehttps://example.com/kerfuffle",
+			wantBody: "seehttps://example.com/kerfuffle",
+		},
+		{
+			name:        "Next to a link",
+			body:        "kerfuffle: https://kerfuffle.example kerfuffle",
+			wantBody:    "****: https://kerfuffle.example ****",
+			wantMatches: []string{"kerfuffle"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filter.Apply(tt.body)
+			if got.Body != tt.wantBody {
+				t.Errorf("Apply() Body = %q, want %q", got.Body, tt.wantBody)
+			}
+			if got.Rejected != tt.wantRejected || got.Flagged != tt.wantFlagged {
+				t.Errorf("Apply() Rejected, Flagged = %v, %v, want %v, %v", got.Rejected, got.Flagged, tt.wantRejected, tt.wantFlagged)
+			}
+			if !reflect.DeepEqual(got.Matches, tt.wantMatches) {
+				t.Errorf("Apply() Matches = %v, want %v", got.Matches, tt.wantMatches)
+			}
+		})
+	}
+}
+
+func TestNewFilterInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{name: "Empty word", rule: Rule{Word: " ", Action: ActionMask}},
+		{name: "Phrase", rule: Rule{Word: "two words", Action: ActionMask}},
+		{name: "Unknown action", rule: Rule{Word: "word", Action: "delete"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFilter([]Rule{tt.rule}); err == nil {
+				t.Errorf("NewFilter(%+v) error = nil, want error", tt.rule)
+			}
+		})
+	}
+}
+
+func TestLoadFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filter.json")
+	err := os.WriteFile(path, []byte(`{"rules": [{"word": "fornax", "action": "reject"}]}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := LoadFilter(path)
+	if err != nil {
+		t.Fatalf("LoadFilter() error = %v", err)
+	}
+	if !filter.Apply("Fornax?").Rejected {
+		t.Errorf("LoadFilter() filter did not reject a listed word")
+	}
+}
@@ -0,0 +1,8 @@
+module chirptext
+
+go 1.23.6
+
+require (
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/text v0.23.0
+)
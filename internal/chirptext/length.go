@@ -0,0 +1,58 @@
+// Package chirptext measures and normalises chirp bodies the way users
+// perceive them rather than byte by byte.
+package chirptext
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// URLWeight is how many characters a link counts for, however long it is,
+// so that long URLs do not eat into the limit.
+const URLWeight = 23
+
+var urlPattern = regexp.MustCompile(`https?://[^\s]+`)
+
+// LengthError reports a chirp over its character limit.
+type LengthError struct {
+	Length int
+	Limit  int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("chirp is %d characters, %d over the limit of %d", e.Length, e.OverBy(), e.Limit)
+}
+
+// OverBy is how many characters need removing for the chirp to fit.
+func (e *LengthError) OverBy() int {
+	return e.Length - e.Limit
+}
+
+// Normalize returns body in Unicode NFC, so that visually identical chirps
+// are stored, counted and searched identically.
+func Normalize(body string) string {
+	return norm.NFC.String(body)
+}
+
+// Length counts body in user-perceived characters (grapheme clusters), with
+// every URL counting as URLWeight.
+func Length(body string) int {
+	length := 0
+	last := 0
+	for _, match := range urlPattern.FindAllStringIndex(body, -1) {
+		length += uniseg.GraphemeClusterCount(body[last:match[0]]) + URLWeight
+		last = match[1]
+	}
+	return length + uniseg.GraphemeClusterCount(body[last:])
+}
+
+// Validate returns a *LengthError if body is longer than limit.
+func Validate(body string, limit int) error {
+	if length := Length(body); length > limit {
+		return &LengthError{Length: length, Limit: limit}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package chirptext
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "ASCII", body: "hello world", want: 11},
+		{name: "CJK", body: "你好世界", want: 4},
+		{name: "Emoji", body: strings.Repeat("😀", 50), want: 50},
+		{name: "Family emoji", body: "👨‍👩‍👧‍👦", want: 1},
+		{name: "Flag", body: "🇳🇿", want: 1},
+		{name: "Combining accent", body: "é", want: 1},
+		{name: "URL", body: "see https://example.com/" + strings.Repeat("a", 100), want: 4 + URLWeight},
+		{name: "Two URLs", body: "http://a.io and https://b.io/x", want: URLWeight + 5 + URLWeight},
+		{name: "Empty", body: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Length(tt.body); got != tt.want {
+				t.Errorf("Length(%q) = %d, want %d", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("é"); got != "é" {
+		t.Errorf("Normalize() = %q, want %q", got, "é")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(strings.Repeat("😀", 140), 140); err != nil {
+		t.Errorf("Validate() at the limit error = %v", err)
+	}
+
+	err := Validate(strings.Repeat("字", 145), 140)
+	var lengthErr *LengthError
+	if !errors.As(err, &lengthErr) {
+		t.Fatalf("Validate() error = %v, want *LengthError", err)
+	}
+	if lengthErr.Length != 145 || lengthErr.Limit != 140 || lengthErr.OverBy() != 5 {
+		t.Errorf("Validate() = %+v, want length 145 over by 5", lengthErr)
+	}
+}
@@ -0,0 +1,104 @@
+package chirptext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchQuery converts search box syntax into an expression for Postgres'
+// to_tsquery. Terms are ANDed together; "quoted phrases" must appear in
+// order, a trailing * matches any word with that prefix, a leading -
+// excludes a term and OR between two terms accepts either. Only letters,
+// marks and digits are passed through, so the result is always a valid
+// tsquery. It returns "" if q contains nothing searchable.
+func SearchQuery(q string) string {
+	var groups [][]string
+	pendingOr := false
+
+	for i := 0; i < len(q); {
+		r, size := utf8.DecodeRuneInString(q[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		negate := false
+		if r == '-' {
+			negate = true
+			i += size
+			if i >= len(q) {
+				break
+			}
+			r, size = utf8.DecodeRuneInString(q[i:])
+		}
+
+		var words []string
+		prefix := false
+		if r == '"' {
+			phrase := q[i+size:]
+			if end := strings.IndexByte(phrase, '"'); end >= 0 {
+				phrase = phrase[:end]
+				i += size + end + 1
+			} else {
+				i = len(q)
+			}
+			words = searchWords(phrase)
+		} else {
+			end := i
+			for end < len(q) {
+				r, size := utf8.DecodeRuneInString(q[end:])
+				if unicode.IsSpace(r) || r == '"' {
+					break
+				}
+				end += size
+			}
+			raw := q[i:end]
+			i = end
+			if raw == "OR" && len(groups) > 0 && !pendingOr {
+				pendingOr = true
+				continue
+			}
+			prefix = strings.HasSuffix(raw, "*")
+			words = searchWords(raw)
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		// Words split out of one token, like "e-mail", are kept adjacent.
+		if prefix {
+			words[len(words)-1] += ":*"
+		}
+		term := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			term = "(" + term + ")"
+		}
+		if negate {
+			term = "!" + term
+		}
+
+		if pendingOr {
+			groups[len(groups)-1] = append(groups[len(groups)-1], term)
+			pendingOr = false
+		} else {
+			groups = append(groups, []string{term})
+		}
+	}
+
+	terms := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if len(group) == 1 {
+			terms = append(terms, group[0])
+		} else {
+			terms = append(terms, "("+strings.Join(group, " | ")+")")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !isWordRune(r)
+	})
+}
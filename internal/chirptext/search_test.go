@@ -0,0 +1,36 @@
+package chirptext
+
+import "testing"
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		want string
+	}{
+		{name: "Single word", q: "chirpy", want: "chirpy"},
+		{name: "Words", q: "boot dev", want: "boot & dev"},
+		{name: "Case", q: "Boot DEV", want: "boot & dev"},
+		{name: "Phrase", q: `"hello world"`, want: "(hello <-> world)"},
+		{name: "Phrase and word", q: `go "type parameters"`, want: "go & (type <-> parameters)"},
+		{name: "Unterminated phrase", q: `"hello world`, want: "(hello <-> world)"},
+		{name: "Prefix", q: "prog*", want: "prog:*"},
+		{name: "Exclude", q: "go -java", want: "go & !java"},
+		{name: "Exclude phrase", q: `go -"java beans"`, want: "go & !(java <-> beans)"},
+		{name: "OR", q: "cats OR dogs pets", want: "(cats | dogs) & pets"},
+		{name: "Chained OR", q: "a1 OR b2 OR c3", want: "(a1 | b2 | c3)"},
+		{name: "Leading OR", q: "OR cats", want: "or & cats"},
+		{name: "Hyphenated", q: "e-mail", want: "(e <-> mail)"},
+		{name: "Operators stripped", q: "a&b | !c:* (d)", want: "(a <-> b) & c:* & d"},
+		{name: "Unicode", q: "café 日本", want: "café & 日本"},
+		{name: "Nothing searchable", q: `  - "" * `, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SearchQuery(tt.q); got != tt.want {
+				t.Errorf("SearchQuery(%q) = %q, want %q", tt.q, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,28 +11,32 @@ import (
 
 const getActiveRefreshToken = `-- name: GetActiveRefreshToken :one
 SELECT
-          token
+          token_hash
           ,created_at
           ,updated_at
           ,user_id
           ,expires_at
           ,revoked_at
+          ,family_id
+          ,parent_token_hash
 FROM      refresh_tokens
-WHERE     token = $1
+WHERE     token_hash = $1
       AND NOW() < expires_at
       AND revoked_at IS NULL
 `
 
-func (q *Queries) GetActiveRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, getActiveRefreshToken, token)
+func (q *Queries) GetActiveRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	row := q.db.QueryRowContext(ctx, getActiveRefreshToken, tokenHash)
 	var i RefreshToken
 	err := row.Scan(
-		&i.Token,
+		&i.TokenHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ParentTokenHash,
 	)
 	return i, err
 }
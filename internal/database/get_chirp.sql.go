@@ -18,6 +18,11 @@ SELECT
           ,updated_at
           ,body
           ,user_id
+          ,edit_count
+          ,in_reply_to
+          ,reply_count
+          ,like_count
+          ,rechirp_count
 FROM      chirps
 WHERE     id = $1
 `
@@ -31,6 +36,11 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.EditCount,
+		&i.InReplyTo,
+		&i.ReplyCount,
+		&i.LikeCount,
+		&i.RechirpCount,
 	)
 	return i, err
 }
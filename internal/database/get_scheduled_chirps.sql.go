@@ -1,40 +1,45 @@
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
 //   sqlc v1.28.0
-// source: get_chirps.sql
+// source: get_scheduled_chirps.sql
 
 package database
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
-const getChirps = `-- name: GetChirps :many
+const getScheduledChirps = `-- name: GetScheduledChirps :many
 SELECT
           id
           ,created_at
           ,updated_at
           ,body
           ,user_id
-FROM      chirps
-ORDER BY  created_at ASC
+          ,publish_at
+FROM      scheduled_chirps
+WHERE     user_id = $1
+ORDER BY  publish_at ASC, id ASC
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+func (q *Queries) GetScheduledChirps(ctx context.Context, userID uuid.UUID) ([]ScheduledChirp, error) {
+	rows, err := q.db.QueryContext(ctx, getScheduledChirps, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Chirp
+	var items []ScheduledChirp
 	for rows.Next() {
-		var i Chirp
+		var i ScheduledChirp
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.PublishAt,
 		); err != nil {
 			return nil, err
 		}
@@ -17,6 +17,8 @@ SELECT
           ,email
           ,hashed_password
           ,is_chirpy_red
+          ,email_verified_at
+          ,handle
 FROM      users
 WHERE     email = $1
 `
@@ -31,6 +33,8 @@ func (q *Queries) GetUserFromEmail(ctx context.Context, email string) (User, err
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.EmailVerifiedAt,
+		&i.Handle,
 	)
 	return i, err
 }
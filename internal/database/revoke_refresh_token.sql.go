@@ -13,10 +13,10 @@ const revokeRefreshToken = `-- name: RevokeRefreshToken :exec
 UPDATE    refresh_tokens
 SET       revoked_at = NOW(),
           updated_at = NOW()
-WHERE     token = $1
+WHERE     token_hash = $1
 `
 
-func (q *Queries) RevokeRefreshToken(ctx context.Context, token string) error {
-	_, err := q.db.ExecContext(ctx, revokeRefreshToken, token)
+func (q *Queries) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	_, err := q.db.ExecContext(ctx, revokeRefreshToken, tokenHash)
 	return err
 }
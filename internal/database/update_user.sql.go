@@ -15,9 +15,10 @@ const updateUser = `-- name: UpdateUser :one
 UPDATE  users
 SET     email = $1,
         hashed_password = $2,
+        email_verified_at = CASE WHEN email = $1 THEN email_verified_at ELSE NULL END,
         updated_at = NOW()
 WHERE   id = $3
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, email_verified_at, handle
 `
 
 type UpdateUserParams struct {
@@ -36,6 +37,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.EmailVerifiedAt,
+		&i.Handle,
 	)
 	return i, err
 }
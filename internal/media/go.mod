@@ -0,0 +1,5 @@
+module media
+
+go 1.23.6
+
+require golang.org/x/image v0.25.0
@@ -0,0 +1,337 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrTooLarge        = errors.New("image is too large")
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrInvalidImage    = errors.New("image could not be decoded")
+)
+
+// Limits bounds the images Process accepts and the thumbnails it makes.
+type Limits struct {
+	MaxBytes int
+	// MaxPixels caps width times height, summed over the frames of an
+	// animated GIF, so a small file cannot decode into a huge bitmap.
+	MaxPixels     int
+	ThumbnailSize int
+}
+
+var DefaultLimits = Limits{
+	MaxBytes:      8 << 20,
+	MaxPixels:     40_000_000,
+	ThumbnailSize: 400,
+}
+
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// Processed is an upload ready to store: the original re-encoded without
+// its metadata, and a thumbnail that fits within Limits.ThumbnailSize.
+type Processed struct {
+	Original  Image
+	Thumbnail Image
+}
+
+// Process checks that data is a JPEG, PNG, GIF or WebP image within limits
+// and re-encodes it. Re-encoding drops EXIF and other metadata, such as GPS
+// coordinates, after the EXIF orientation has been applied to the pixels.
+// WebP images are stored as JPEG, or PNG if they have transparency, since
+// there is no WebP encoder in the standard library.
+func Process(data []byte, limits Limits) (Processed, error) {
+	if len(data) > limits.MaxBytes {
+		return Processed{}, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+	default:
+		return Processed{}, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, ErrInvalidImage
+	}
+	if config.Width*config.Height > limits.MaxPixels {
+		return Processed{}, ErrTooLarge
+	}
+
+	if contentType == "image/gif" {
+		return processGIF(data, config, limits)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, ErrInvalidImage
+	}
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	var original Image
+	if contentType == "image/png" {
+		original, err = encodePNG(img)
+	} else {
+		original, err = encodeStill(img, 90)
+	}
+	if err != nil {
+		return Processed{}, err
+	}
+	thumbnail, err := encodeStill(thumbnail(img, limits.ThumbnailSize), 80)
+	if err != nil {
+		return Processed{}, err
+	}
+	return Processed{Original: original, Thumbnail: thumbnail}, nil
+}
+
+// processGIF keeps animations. Re-encoding keeps the frames, timing and loop
+// count but no comments or other extensions.
+func processGIF(data []byte, config image.Config, limits Limits) (Processed, error) {
+	// DecodeAll allocates every frame, so count them before decoding.
+	frames, err := gifFrameCount(data)
+	if err != nil || frames == 0 {
+		return Processed{}, ErrInvalidImage
+	}
+	if frames*config.Width*config.Height > limits.MaxPixels {
+		return Processed{}, ErrTooLarge
+	}
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil || len(g.Image) == 0 {
+		return Processed{}, ErrInvalidImage
+	}
+
+	var buf bytes.Buffer
+	err = gif.EncodeAll(&buf, &gif.GIF{
+		Image:           g.Image,
+		Delay:           g.Delay,
+		LoopCount:       g.LoopCount,
+		Disposal:        g.Disposal,
+		Config:          g.Config,
+		BackgroundIndex: g.BackgroundIndex,
+	})
+	if err != nil {
+		return Processed{}, err
+	}
+	original := Image{
+		ContentType: "image/gif",
+		Data:        buf.Bytes(),
+		Width:       config.Width,
+		Height:      config.Height,
+	}
+	thumbnail, err := encodeStill(thumbnail(g.Image[0], limits.ThumbnailSize), 80)
+	if err != nil {
+		return Processed{}, err
+	}
+	return Processed{Original: original, Thumbnail: thumbnail}, nil
+}
+
+// thumbnail scales img down to fit within size by size, keeping its aspect
+// ratio. Smaller images keep their size.
+func thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// encodeStill uses JPEG for opaque images and PNG for anything with
+// transparency.
+func encodeStill(img image.Image, quality int) (Image, error) {
+	if !isOpaque(img) {
+		return encodePNG(img)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return Image{}, err
+	}
+	return newImage("image/jpeg", buf.Bytes(), img), nil
+}
+
+func encodePNG(img image.Image) (Image, error) {
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, img); err != nil {
+		return Image{}, err
+	}
+	return newImage("image/png", buf.Bytes(), img), nil
+}
+
+func newImage(contentType string, data []byte, img image.Image) Image {
+	bounds := img.Bounds()
+	return Image{ContentType: contentType, Data: data, Width: bounds.Dx(), Height: bounds.Dy()}
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// gifFrameCount counts the image descriptors of a GIF by walking its blocks,
+// skipping the color tables and image data without decoding them.
+func gifFrameCount(data []byte) (int, error) {
+	// Header and logical screen descriptor.
+	if len(data) < 13 {
+		return 0, ErrInvalidImage
+	}
+	i := 13 + colorTableSize(data[10])
+	frames := 0
+	for {
+		if i >= len(data) {
+			return 0, ErrInvalidImage
+		}
+		switch data[i] {
+		case 0x21: // extension: introducer, label, sub-blocks
+			i += 2
+		case 0x2C: // image descriptor, local color table, LZW code size, sub-blocks
+			if i+10 > len(data) {
+				return 0, ErrInvalidImage
+			}
+			i += 10 + colorTableSize(data[i+9]) + 1
+			frames++
+		case 0x3B: // trailer
+			return frames, nil
+		default:
+			return 0, ErrInvalidImage
+		}
+		for {
+			if i >= len(data) {
+				return 0, ErrInvalidImage
+			}
+			size := int(data[i])
+			i += 1 + size
+			if size == 0 {
+				break
+			}
+		}
+	}
+}
+
+// colorTableSize returns the length in bytes of the color table described
+// by a GIF packed fields byte.
+func colorTableSize(fields byte) int {
+	if fields&0x80 == 0 {
+		return 0
+	}
+	return 3 << (fields&0x07 + 1)
+}
+
+// jpegOrientation returns the EXIF orientation tag of a JPEG, or 1 (upright)
+// if it has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		// Metadata segments all come before the start of scan.
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation reads tag 0x0112 from IFD0 of a TIFF structure.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// applyOrientation rotates and flips img so that it displays upright once
+// the orientation tag is gone.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	// source maps a pixel of the upright image back to the stored one.
+	source := map[int]func(x, y int) (int, int){
+		2: func(x, y int) (int, int) { return w - 1 - x, y },
+		3: func(x, y int) (int, int) { return w - 1 - x, h - 1 - y },
+		4: func(x, y int) (int, int) { return x, h - 1 - y },
+		5: func(x, y int) (int, int) { return y, x },
+		6: func(x, y int) (int, int) { return y, h - 1 - x },
+		7: func(x, y int) (int, int) { return w - 1 - y, h - 1 - x },
+		8: func(x, y int) (int, int) { return w - 1 - y, x },
+	}[orientation]
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := source(x, y)
+			dst.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,229 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"runtime"
+	"testing"
+)
+
+func encodeTestPNG(t *testing.T, w, h int, alpha uint8) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 100, A: alpha})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encodeTestJPEG returns a w by h JPEG whose left half is black and right
+// half white, with an EXIF orientation tag if orientation is non-zero.
+func encodeTestJPEG(t *testing.T, w, h int, orientation uint16) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x >= w/2 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if orientation == 0 {
+		return data
+	}
+
+	exif := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x01" +
+		"\x01\x12\x00\x03\x00\x00\x00\x01")
+	exif = append(exif, byte(orientation>>8), byte(orientation), 0, 0)
+	exif = append(exif, 0, 0, 0, 0)
+	segment := []byte{0xFF, 0xE1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}
+	segment = append(segment, exif...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestProcessRejects(t *testing.T) {
+	limits := Limits{MaxBytes: 1 << 20, MaxPixels: 10_000, ThumbnailSize: 50}
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("hello, world"), ErrUnsupportedType},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), ErrUnsupportedType},
+		{"truncated png", encodeTestPNG(t, 10, 10, 255)[:40], ErrInvalidImage},
+		{"too many pixels", encodeTestPNG(t, 200, 100, 255), ErrTooLarge},
+		{"too many bytes", append(encodeTestPNG(t, 10, 10, 255), make([]byte, 1<<20)...), ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Process(tt.data, limits)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Process() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessThumbnail(t *testing.T) {
+	tests := []struct {
+		name                   string
+		data                   []byte
+		wantType, wantThumb    string
+		wantThumbW, wantThumbH int
+	}{
+		{"wide opaque png", encodeTestPNG(t, 300, 150, 255), "image/png", "image/jpeg", 100, 50},
+		{"tall transparent png", encodeTestPNG(t, 60, 240, 128), "image/png", "image/png", 25, 100},
+		{"small jpeg", encodeTestJPEG(t, 40, 30, 0), "image/jpeg", "image/jpeg", 40, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := Process(tt.data, Limits{MaxBytes: 1 << 20, MaxPixels: 1 << 20, ThumbnailSize: 100})
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if processed.Original.ContentType != tt.wantType {
+				t.Errorf("Original.ContentType = %q, want %q", processed.Original.ContentType, tt.wantType)
+			}
+			thumb := processed.Thumbnail
+			if thumb.ContentType != tt.wantThumb || thumb.Width != tt.wantThumbW || thumb.Height != tt.wantThumbH {
+				t.Errorf("Thumbnail = %s %dx%d, want %s %dx%d",
+					thumb.ContentType, thumb.Width, thumb.Height, tt.wantThumb, tt.wantThumbW, tt.wantThumbH)
+			}
+			config, _, err := image.DecodeConfig(bytes.NewReader(thumb.Data))
+			if err != nil || config.Width != thumb.Width || config.Height != thumb.Height {
+				t.Errorf("Thumbnail.Data decodes to %+v, %v", config, err)
+			}
+		})
+	}
+}
+
+func TestProcessStripsEXIF(t *testing.T) {
+	data := encodeTestJPEG(t, 40, 20, 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Fatalf("jpegOrientation() = %d, want 6", got)
+	}
+
+	processed, err := Process(data, DefaultLimits)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	original := processed.Original
+	if bytes.Contains(original.Data, []byte("Exif")) {
+		t.Error("Original.Data still contains EXIF")
+	}
+	// Orientation 6 is a quarter turn clockwise, so the 40x20 image with a
+	// white right half becomes 20x40 with a white bottom half.
+	if original.Width != 20 || original.Height != 40 {
+		t.Fatalf("Original is %dx%d, want 20x40", original.Width, original.Height)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(original.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, _, _, _ := img.At(10, 5).RGBA()
+	bottom, _, _, _ := img.At(10, 35).RGBA()
+	if top > 0x4000 || bottom < 0xC000 {
+		t.Errorf("pixels not rotated: top = %#x, bottom = %#x", top, bottom)
+	}
+}
+
+func TestProcessKeepsGIFAnimation(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{LoopCount: 0}
+	for i := 0; i < 3; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 20, 10), palette)
+		frame.SetColorIndex(i, 0, 1)
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	processed, err := Process(buf.Bytes(), DefaultLimits)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if processed.Original.ContentType != "image/gif" {
+		t.Fatalf("Original.ContentType = %q", processed.Original.ContentType)
+	}
+	decoded, err := gif.DecodeAll(bytes.NewReader(processed.Original.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Image) != 3 || decoded.Delay[2] != 10 {
+		t.Errorf("decoded %d frames with delays %v, want 3 frames", len(decoded.Image), decoded.Delay)
+	}
+}
+
+func TestProcessRejectsGIFFramesBeforeDecoding(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	frame := image.NewPaletted(image.Rect(0, 0, 500, 500), palette)
+	g := &gif.GIF{}
+	for i := 0; i < 200; i++ {
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 0)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	limits := DefaultLimits
+	limits.MaxPixels = 10_000_000
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := Process(buf.Bytes(), limits)
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Process() error = %v, want %v", err, ErrTooLarge)
+	}
+	// Decoding would allocate 200 frames of 250,000 pixels.
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 10<<20 {
+		t.Errorf("Process() allocated %d bytes before rejecting the GIF", allocated)
+	}
+}
+
+func TestGIFFrameCount(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{}
+	for i := 0; i < 5; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 8, 8), palette))
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	frames, err := gifFrameCount(data)
+	if err != nil || frames != 5 {
+		t.Errorf("gifFrameCount() = %d, %v, want 5 frames", frames, err)
+	}
+	if _, err := gifFrameCount(data[:len(data)-1]); err == nil {
+		t.Error("gifFrameCount() accepted a GIF without its trailer")
+	}
+}
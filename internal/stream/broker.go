@@ -0,0 +1,156 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrSlowConsumer ends a subscription whose buffer filled up because it was
+// not reading events as fast as they were published. The subscriber can
+// resubscribe from the last event it handled.
+var ErrSlowConsumer = errors.New("subscriber fell too far behind")
+
+// Event is a message fanned out to subscribers. Topics say what it concerns,
+// such as "user:<id>" for the author of a chirp, and are what subscribers
+// usually filter on. Data is the payload, typically JSON.
+type Event struct {
+	ID     uint64
+	Type   string
+	Topics []string
+	Data   []byte
+}
+
+func (e Event) HasTopic(topic string) bool {
+	return slices.Contains(e.Topics, topic)
+}
+
+// Broker fans events out to subscribers. MemoryBroker does so within one
+// process; a broker backed by Postgres LISTEN/NOTIFY can implement the same
+// interface so that several server instances share their events.
+type Broker interface {
+	Publish(ctx context.Context, eventType string, topics []string, data []byte) (Event, error)
+	// Subscribe delivers events published after the event with ID after, or
+	// only new events if after is zero, for which match returns true. A nil
+	// match receives everything.
+	Subscribe(after uint64, match func(Event) bool) *Subscription
+}
+
+type Subscription struct {
+	// Replay holds the retained events after the requested ID, oldest
+	// first. They are not repeated on Events.
+	Replay []Event
+	// Missed is set when some events after the requested ID are no longer
+	// retained, so the subscriber should reload its state instead of
+	// relying on Replay.
+	Missed bool
+
+	events chan Event
+	match  func(Event) bool
+	err    error
+	broker *MemoryBroker
+}
+
+// Events is closed when the subscription ends, after which Err says why.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err is ErrSlowConsumer if the broker ended the subscription, and nil if
+// it was closed by the subscriber.
+func (s *Subscription) Err() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s, nil)
+}
+
+// MemoryBroker keeps the last historySize events for subscribers resuming
+// after a disconnect, and buffers up to bufferSize undelivered events per
+// subscriber. Publish never blocks on a subscriber: one that falls further
+// behind is dropped with ErrSlowConsumer.
+type MemoryBroker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []Event
+	historySize int
+	bufferSize  int
+	subs        map[*Subscription]struct{}
+}
+
+func NewMemoryBroker(historySize, bufferSize int) *MemoryBroker {
+	return &MemoryBroker{
+		// Starting from the clock keeps IDs increasing across restarts, so
+		// an ID from before one is recognised as missed history rather than
+		// mistaken for a recent event.
+		lastID:      uint64(time.Now().UnixMicro()),
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subs:        make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, eventType string, topics []string, data []byte) (Event, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Topics: topics, Data: data}
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, event)
+	}
+
+	for sub := range b.subs {
+		if sub.match != nil && !sub.match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub, ErrSlowConsumer)
+		}
+	}
+	return event, nil
+}
+
+func (b *MemoryBroker) Subscribe(after uint64, match func(Event) bool) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		events: make(chan Event, b.bufferSize),
+		match:  match,
+		broker: b,
+	}
+	if after != 0 {
+		oldest := b.lastID - uint64(len(b.history)) + 1
+		sub.Missed = after > b.lastID || after+1 < oldest
+		for _, event := range b.history {
+			if event.ID > after && (match == nil || match(event)) {
+				sub.Replay = append(sub.Replay, event)
+			}
+		}
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// remove must be called with b.mu held.
+func (b *MemoryBroker) remove(sub *Subscription, err error) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	sub.err = err
+	close(sub.events)
+}
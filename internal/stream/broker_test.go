@@ -0,0 +1,111 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func publish(t *testing.T, b Broker, eventType string, topics ...string) Event {
+	t.Helper()
+	event, err := b.Publish(context.Background(), eventType, topics, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	return event
+}
+
+func TestMemoryBrokerFiltersByTopic(t *testing.T) {
+	b := NewMemoryBroker(10, 10)
+	sub := b.Subscribe(0, func(e Event) bool { return e.HasTopic("user:a") })
+	defer sub.Close()
+
+	publish(t, b, "chirp.created", "user:b")
+	want := publish(t, b, "chirp.created", "user:a", "tag:go")
+
+	got := <-sub.Events()
+	if got.ID != want.ID {
+		t.Errorf("received event %d, want %d", got.ID, want.ID)
+	}
+	select {
+	case extra := <-sub.Events():
+		t.Errorf("received unexpected event %+v", extra)
+	default:
+	}
+}
+
+func TestMemoryBrokerReplay(t *testing.T) {
+	b := NewMemoryBroker(3, 10)
+	var events []Event
+	for i := 0; i < 5; i++ {
+		events = append(events, publish(t, b, "chirp.created"))
+	}
+
+	tests := []struct {
+		name       string
+		after      uint64
+		wantReplay int
+		wantMissed bool
+	}{
+		{"new events only", 0, 0, false},
+		{"up to date", events[4].ID, 0, false},
+		{"within history", events[2].ID, 2, false},
+		{"oldest retained", events[1].ID, 3, false},
+		{"beyond history", events[0].ID, 3, true},
+		{"unknown future ID", events[4].ID + 100, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := b.Subscribe(tt.after, nil)
+			defer sub.Close()
+			if len(sub.Replay) != tt.wantReplay || sub.Missed != tt.wantMissed {
+				t.Errorf("Subscribe(%d) replayed %d events, missed %v; want %d, %v",
+					tt.after, len(sub.Replay), sub.Missed, tt.wantReplay, tt.wantMissed)
+			}
+			if len(sub.Replay) > 0 && sub.Replay[len(sub.Replay)-1].ID != events[4].ID {
+				t.Errorf("Replay ends at %d, want %d", sub.Replay[len(sub.Replay)-1].ID, events[4].ID)
+			}
+		})
+	}
+}
+
+func TestMemoryBrokerDropsSlowConsumers(t *testing.T) {
+	b := NewMemoryBroker(0, 2)
+	slow := b.Subscribe(0, nil)
+	fast := b.Subscribe(0, nil)
+	defer fast.Close()
+
+	for i := 0; i < 3; i++ {
+		publish(t, b, "chirp.created")
+		<-fast.Events()
+	}
+
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	if received != 2 {
+		t.Errorf("slow subscriber received %d events before being dropped, want 2", received)
+	}
+	if !errors.Is(slow.Err(), ErrSlowConsumer) {
+		t.Errorf("Err() = %v, want ErrSlowConsumer", slow.Err())
+	}
+	if fast.Err() != nil {
+		t.Errorf("fast subscriber Err() = %v", fast.Err())
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	b := NewMemoryBroker(0, 1)
+	sub := b.Subscribe(0, nil)
+	sub.Close()
+	sub.Close()
+
+	publish(t, b, "chirp.deleted")
+	if _, ok := <-sub.Events(); ok {
+		t.Error("closed subscription received an event")
+	}
+	if sub.Err() != nil {
+		t.Errorf("Err() = %v, want nil", sub.Err())
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"internal/database"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// throttlePolicy describes how failed login attempts against one key are
+// slowed down: the first few are free, then each further failure doubles the
+// wait, and past lockoutAfter the key is locked out outright.
+type throttlePolicy struct {
+	freeAttempts int32
+	lockoutAfter int32
+	lockout      time.Duration
+}
+
+var (
+	accountThrottle = throttlePolicy{freeAttempts: 3, lockoutAfter: 10, lockout: 15 * time.Minute}
+	// Many users can share an address behind NAT, so the per-IP policy is
+	// far more forgiving and mainly stops a single host spraying passwords.
+	ipThrottle = throttlePolicy{freeAttempts: 20, lockoutAfter: 100, lockout: 15 * time.Minute}
+)
+
+func (p throttlePolicy) delay(failures int32) time.Duration {
+	if failures >= p.lockoutAfter {
+		return p.lockout
+	}
+	if failures < p.freeAttempts {
+		return 0
+	}
+	// Past 2^30 seconds the shift would soon overflow time.Duration, and the
+	// delay is far beyond any lockout anyway.
+	if n := failures - p.freeAttempts; n < 30 {
+		return min(time.Second<<n, p.lockout)
+	}
+	return p.lockout
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func mfaThrottleKey(userID string) string {
+	return "mfa:" + userID
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// clientIP is the address of the caller, taken from X-Forwarded-For only when
+// the server is configured to sit behind a trusted proxy.
+func (cfg *apiConfig) clientIP(r *http.Request) string {
+	if cfg.trustProxyHeaders {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			client, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(client)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// reserveLoginAttempt counts an attempt against key, held to the account
+// policy, and ipKey before the credentials are checked, and responds with 429
+// and returns false when either is locked out. The attempt is counted and any
+// backoff it earns is locked in as though it will fail, all in a transaction
+// holding both rows, so concurrent guesses wait for each other rather than all
+// passing the same check. A successful attempt is handed back with
+// releaseLoginAttempt.
+func (cfg *apiConfig) reserveLoginAttempt(w http.ResponseWriter, r *http.Request, key, ipKey string) bool {
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return false
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	policies := []struct {
+		key    string
+		policy throttlePolicy
+	}{
+		{key, accountThrottle},
+		{ipKey, ipThrottle},
+	}
+	failures := make([]int32, len(policies))
+	retryAfter := int32(0)
+	for i, p := range policies {
+		attempt, err := qtx.ReserveLoginAttempt(r.Context(), p.key)
+		if err != nil {
+			log.Printf("Error checking login throttle: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return false
+		}
+		failures[i] = attempt.Failures
+		retryAfter = max(retryAfter, attempt.RetryAfterSeconds)
+	}
+	// Rolling back leaves attempts turned away uncounted.
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+		respondWithError(w, 429, "Too many failed attempts, try again later")
+		return false
+	}
+
+	for i, p := range policies {
+		delay := p.policy.delay(failures[i])
+		if delay == 0 {
+			continue
+		}
+		err = qtx.LockLogin(r.Context(), database.LockLoginParams{
+			LockoutSeconds: delay.Seconds(),
+			Key:            p.key,
+		})
+		if err != nil {
+			log.Printf("Error locking login: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return false
+		}
+		if failures[i] == p.policy.lockoutAfter {
+			log.Printf("SECURITY: %s locked out after %d failed login attempts", p.key, failures[i])
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error checking login throttle: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return false
+	}
+	return true
+}
+
+// releaseLoginAttempt undoes a reservation once the credentials turn out to
+// be right: the account's failures are forgiven outright, while the address
+// only gets this one attempt back.
+func (cfg *apiConfig) releaseLoginAttempt(ctx context.Context, key, ipKey string) {
+	err := cfg.db.ClearLoginThrottle(ctx, key)
+	if err != nil {
+		log.Printf("Error clearing login throttle: %s", err)
+	}
+	err = cfg.db.ReleaseLoginAttempt(ctx, ipKey)
+	if err != nil {
+		log.Printf("Error clearing login throttle: %s", err)
+	}
+}
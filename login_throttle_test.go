@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottlePolicyDelay(t *testing.T) {
+	policies := []struct {
+		name   string
+		policy throttlePolicy
+	}{
+		{"account", accountThrottle},
+		{"ip", ipThrottle},
+	}
+	for _, tt := range policies {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.policy
+			var previous time.Duration
+			for failures := int32(0); failures <= p.lockoutAfter; failures++ {
+				got := p.delay(failures)
+				switch {
+				case failures < p.freeAttempts && got != 0:
+					t.Errorf("delay(%d) = %v, want 0 for a free attempt", failures, got)
+				case failures >= p.lockoutAfter && got != p.lockout:
+					t.Errorf("delay(%d) = %v, want the %v lockout", failures, got, p.lockout)
+				case failures >= p.freeAttempts && got <= 0:
+					t.Errorf("delay(%d) = %v, want a positive delay", failures, got)
+				case got > p.lockout:
+					t.Errorf("delay(%d) = %v, longer than the %v lockout", failures, got, p.lockout)
+				case got < previous:
+					t.Errorf("delay(%d) = %v, shorter than delay(%d) = %v", failures, got, failures-1, previous)
+				}
+				previous = got
+			}
+		})
+	}
+}
+
+func TestLoginThrottleConcurrentGuesses(t *testing.T) {
+	cfg := newTestConfig(t)
+	user := createTestUser(t, cfg, "correct horse")
+	// A fresh address keeps the per-IP counter out of the way.
+	remoteAddr := fmt.Sprintf("198.51.100.%d:1234", rand.IntN(256))
+	t.Cleanup(func() {
+		cfg.db.ClearLoginThrottle(context.Background(), accountThrottleKey(user.Email))
+		cfg.db.ClearLoginThrottle(context.Background(), ipThrottleKey(strings.Split(remoteAddr, ":")[0]))
+	})
+
+	const guesses = 20
+	codes := make(chan int, guesses)
+	var wg sync.WaitGroup
+	for range guesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"email":%q,"password":"wrong"}`, user.Email)
+			req := httptest.NewRequest("POST", "/api/login", strings.NewReader(body))
+			req.RemoteAddr = remoteAddr
+			rec := httptest.NewRecorder()
+			cfg.handlerLoginUser(rec, req)
+			codes <- rec.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	evaluated := 0
+	for code := range codes {
+		switch code {
+		case 401:
+			evaluated++
+		case 429:
+		default:
+			t.Errorf("status = %d, want 401 or 429", code)
+		}
+	}
+	if evaluated > int(accountThrottle.freeAttempts) {
+		t.Errorf("%d of %d concurrent guesses were checked, want at most %d",
+			evaluated, guesses, accountThrottle.freeAttempts)
+	}
+}
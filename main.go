@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"internal/auth"
 	"internal/database"
+	"internal/mail"
+	"internal/media"
+	"internal/stream"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -15,40 +24,259 @@ func main() {
 	godotenv.Load()
 	dbURL := os.Getenv("DB_URL")
 	platform := os.Getenv("PLATFORM")
-	jwtSecret := os.Getenv("JWT_SECRET")
 	polkaKey := os.Getenv("POLKA_KEY")
+	polkaSecret := os.Getenv("POLKA_WEBHOOK_SECRET")
+	// The shared ApiKey alone does nothing to stop a captured webhook being
+	// replayed.
+	if polkaSecret == "" && platform != "dev" {
+		log.Fatal("POLKA_WEBHOOK_SECRET must be set outside of dev")
+	}
+	tokenPepper := os.Getenv("REFRESH_TOKEN_PEPPER")
+	// Without a pepper the stored token digests could be checked against
+	// guesses by anyone who reads the database.
+	if tokenPepper == "" && platform != "dev" {
+		log.Fatal("REFRESH_TOKEN_PEPPER must be set outside of dev")
+	}
+	publicURL := os.Getenv("PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:8080"
+	}
+	requireVerifiedEmail := os.Getenv("REQUIRE_VERIFIED_EMAIL") == "true"
+	trustProxyHeaders := os.Getenv("TRUST_PROXY_HEADERS") == "true"
+	adminKey := os.Getenv("ADMIN_API_KEY")
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		log.Fatalf("Error opening database: %v", err)
 	}
 	dbQueries := database.New(db)
 
+	jwtKeys, err := loadJWTKeys()
+	if err != nil {
+		log.Fatalf("Error loading JWT keys: %v", err)
+	}
+
+	argon2Params, err := loadArgon2Params()
+	if err != nil {
+		log.Fatalf("Error loading password hashing parameters: %v", err)
+	}
+	auth.SetArgon2Params(argon2Params)
+
+	tiers, err := loadTiers(os.Getenv("TIERS_FILE"))
+	if err != nil {
+		log.Fatalf("Error loading tier entitlements: %v", err)
+	}
+
 	mux := http.NewServeMux()
 	apiCfg := apiConfig{
-		platform:  platform,
-		db:        dbQueries,
-		jwtSecret: jwtSecret,
-		polkaKey:  polkaKey,
+		platform:             platform,
+		db:                   dbQueries,
+		dbConn:               db,
+		jwtKeys:              jwtKeys,
+		polkaKey:             polkaKey,
+		polkaSecret:          polkaSecret,
+		tokenPepper:          tokenPepper,
+		mailer:               newMailer(platform),
+		publicURL:            publicURL,
+		requireVerifiedEmail: requireVerifiedEmail,
+		trustProxyHeaders:    trustProxyHeaders,
+		adminKey:             adminKey,
+		tiers:                tiers,
+		contentFilterFile:    os.Getenv("CONTENT_FILTER_FILE"),
+		blobs:                newBlobStore(platform),
+		mediaLimits:          media.DefaultLimits,
+		events:               stream.NewMemoryBroker(streamHistorySize, streamBufferSize),
+	}
+	_, err = apiCfg.loadContentFilter()
+	if err != nil {
+		log.Fatalf("Error loading content filter: %v", err)
 	}
+
+	go apiCfg.expireSubscriptions(context.Background(), subscriptionExpiryInterval)
+	go apiCfg.publishScheduledChirps(context.Background(), scheduledChirpInterval)
+	go apiCfg.aggregateTrendingTags(context.Background(), trendingInterval)
+	go apiCfg.sweepAttachments(context.Background(), attachmentSweepInterval)
+
 	handlerApp := http.FileServer(http.Dir("."))
 	handlerApp = http.StripPrefix("/app", handlerApp)
 	mux.Handle("/app/", apiCfg.middlewareMetricsInc(handlerApp))
 	mux.Handle("GET /api/healthz", http.HandlerFunc(handlerReadiness))
+	mux.Handle("GET /.well-known/jwks.json", http.HandlerFunc(apiCfg.handlerJWKS))
 	mux.Handle("GET /api/chirps", http.HandlerFunc(apiCfg.handlerGetChirps))
 	mux.Handle("GET /api/chirps/{chirpID}", http.HandlerFunc(apiCfg.handlerGetChirp))
 	mux.Handle("DELETE /api/chirps/{chirpID}", http.HandlerFunc(apiCfg.handlerDeleteChirp))
+	mux.Handle("PUT /api/chirps/{chirpID}", http.HandlerFunc(apiCfg.handlerEditChirp))
+	mux.Handle("GET /api/chirps/{chirpID}/revisions", http.HandlerFunc(apiCfg.handlerGetChirpRevisions))
+	mux.Handle("GET /api/chirps/{chirpID}/thread", http.HandlerFunc(apiCfg.handlerGetChirpThread))
+	mux.Handle("POST /api/chirps/{chirpID}/like", http.HandlerFunc(apiCfg.handlerLikeChirp))
+	mux.Handle("DELETE /api/chirps/{chirpID}/like", http.HandlerFunc(apiCfg.handlerUnlikeChirp))
+	mux.Handle("GET /api/chirps/{chirpID}/likes", http.HandlerFunc(apiCfg.handlerGetChirpLikes))
+	mux.Handle("POST /api/chirps/{chirpID}/rechirp", http.HandlerFunc(apiCfg.handlerRechirp))
+	mux.Handle("DELETE /api/chirps/{chirpID}/rechirp", http.HandlerFunc(apiCfg.handlerUndoRechirp))
 	mux.Handle("POST /api/chirps", http.HandlerFunc(apiCfg.handlerPostChirp))
+	mux.Handle("GET /api/chirps/search", http.HandlerFunc(apiCfg.handlerSearchChirps))
+	mux.Handle("GET /api/chirps/scheduled", http.HandlerFunc(apiCfg.handlerGetScheduledChirps))
+	mux.Handle("DELETE /api/chirps/scheduled/{chirpID}", http.HandlerFunc(apiCfg.handlerDeleteScheduledChirp))
+	mux.Handle("POST /api/attachments", http.HandlerFunc(apiCfg.handlerUploadAttachment))
+	mux.Handle("GET /api/attachments/{attachmentID}", http.HandlerFunc(apiCfg.handlerGetAttachment))
+	mux.Handle("GET /api/attachments/{attachmentID}/thumbnail", http.HandlerFunc(apiCfg.handlerGetAttachmentThumbnail))
 	mux.Handle("POST /api/users", http.HandlerFunc(apiCfg.handlerCreateUser))
+	mux.Handle("POST /api/password-reset", http.HandlerFunc(apiCfg.handlerRequestPasswordReset))
+	mux.Handle("POST /api/password-reset/confirm", http.HandlerFunc(apiCfg.handlerConfirmPasswordReset))
+	mux.Handle("POST /api/users/verify-email", http.HandlerFunc(apiCfg.handlerVerifyEmail))
+	mux.Handle("POST /api/users/verify-email/resend", http.HandlerFunc(apiCfg.handlerResendEmailVerification))
 	mux.Handle("PUT /api/users", http.HandlerFunc(apiCfg.handlerUpdateUser))
+	mux.Handle("GET /api/users/{userID}", http.HandlerFunc(apiCfg.handlerGetUserProfile))
+	mux.Handle("POST /api/users/{userID}/follow", http.HandlerFunc(apiCfg.handlerFollowUser))
+	mux.Handle("DELETE /api/users/{userID}/follow", http.HandlerFunc(apiCfg.handlerUnfollowUser))
+	mux.Handle("GET /api/users/{userID}/followers", http.HandlerFunc(apiCfg.handlerGetFollowers))
+	mux.Handle("GET /api/users/{userID}/following", http.HandlerFunc(apiCfg.handlerGetFollowing))
+	mux.Handle("GET /api/users/{userID}/mentions", http.HandlerFunc(apiCfg.handlerGetUserMentions))
+	mux.Handle("GET /api/notifications", http.HandlerFunc(apiCfg.handlerGetNotifications))
+	mux.Handle("POST /api/notifications/read", http.HandlerFunc(apiCfg.handlerMarkNotificationsRead))
+	mux.Handle("POST /api/notifications/{notificationID}/read", http.HandlerFunc(apiCfg.handlerMarkNotificationRead))
+	mux.Handle("GET /api/notifications/preferences", http.HandlerFunc(apiCfg.handlerGetNotificationPreferences))
+	mux.Handle("PUT /api/notifications/preferences", http.HandlerFunc(apiCfg.handlerUpdateNotificationPreferences))
+	mux.Handle("POST /api/conversations", http.HandlerFunc(apiCfg.handlerCreateConversation))
+	mux.Handle("GET /api/conversations", http.HandlerFunc(apiCfg.handlerGetConversations))
+	mux.Handle("GET /api/conversations/{conversationID}", http.HandlerFunc(apiCfg.handlerGetConversation))
+	mux.Handle("GET /api/conversations/{conversationID}/messages", http.HandlerFunc(apiCfg.handlerGetMessages))
+	mux.Handle("POST /api/conversations/{conversationID}/messages", http.HandlerFunc(apiCfg.handlerSendMessage))
+	mux.Handle("POST /api/conversations/{conversationID}/read", http.HandlerFunc(apiCfg.handlerMarkConversationRead))
+	mux.Handle("DELETE /api/conversations/{conversationID}/messages/{messageID}", http.HandlerFunc(apiCfg.handlerDeleteMessage))
+	mux.Handle("GET /api/messages/settings", http.HandlerFunc(apiCfg.handlerGetMessageSettings))
+	mux.Handle("PUT /api/messages/settings", http.HandlerFunc(apiCfg.handlerUpdateMessageSettings))
+	mux.Handle("GET /api/tags/{tag}/chirps", http.HandlerFunc(apiCfg.handlerGetTagChirps))
+	mux.Handle("GET /api/trending", http.HandlerFunc(apiCfg.handlerGetTrending))
+	mux.Handle("GET /api/stream", http.HandlerFunc(apiCfg.handlerStream))
+	mux.Handle("GET /api/ws", http.HandlerFunc(apiCfg.handlerWebSocket))
+	mux.Handle("GET /api/timeline", http.HandlerFunc(apiCfg.handlerGetTimeline))
 	mux.Handle("POST /api/login", http.HandlerFunc(apiCfg.handlerLoginUser))
+	mux.Handle("POST /api/login/mfa", http.HandlerFunc(apiCfg.handlerLoginMFA))
+	mux.Handle("POST /api/mfa/totp", http.HandlerFunc(apiCfg.handlerEnrollTOTP))
+	mux.Handle("POST /api/mfa/totp/confirm", http.HandlerFunc(apiCfg.handlerConfirmTOTP))
 	mux.Handle("POST /api/refresh", http.HandlerFunc(apiCfg.handlerRefresh))
 	mux.Handle("POST /api/revoke", http.HandlerFunc(apiCfg.handlerRevoke))
-	mux.Handle("POST /api/polka/webhooks", http.HandlerFunc(apiCfg.handlerUpgradeUser))
+	mux.Handle("POST /api/polka/webhooks", http.HandlerFunc(apiCfg.handlerPolkaWebhook))
 	mux.Handle("GET /admin/metrics", http.HandlerFunc(apiCfg.handlerMetrics))
 	mux.Handle("POST /admin/reset", http.HandlerFunc(apiCfg.handlerReset))
+	mux.Handle("DELETE /admin/lockouts", http.HandlerFunc(apiCfg.handlerClearLockout))
+	mux.Handle("POST /admin/content-filter/reload", http.HandlerFunc(apiCfg.handlerReloadContentFilter))
+	mux.Handle("GET /admin/chirp-reviews", http.HandlerFunc(apiCfg.handlerGetChirpReviews))
+	mux.Handle("DELETE /admin/chirp-reviews/{chirpID}", http.HandlerFunc(apiCfg.handlerResolveChirpReview))
+	mux.Handle("GET /admin/polka/events", http.HandlerFunc(apiCfg.handlerListPolkaEvents))
+	mux.Handle("POST /admin/polka/events/{eventID}/replay", http.HandlerFunc(apiCfg.handlerReplayPolkaEvent))
 	server := http.Server{
 		Addr:    ":8080",
 		Handler: mux,
 	}
 	server.ListenAndServe()
 }
+
+// loadJWTKeys reads the access token signing key from JWT_SIGNING_KEY_FILE and
+// any keys still trusted for verification from the comma separated
+// JWT_VERIFICATION_KEY_FILES. Without a signing key an ephemeral one is
+// generated, which is only suitable for local development.
+func loadJWTKeys() (*auth.KeySet, error) {
+	signingKeyFile := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if signingKeyFile == "" {
+		log.Printf("JWT_SIGNING_KEY_FILE not set, generating an ephemeral signing key")
+		return auth.GenerateKeySet()
+	}
+	var verificationKeyFiles []string
+	for _, file := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			verificationKeyFiles = append(verificationKeyFiles, file)
+		}
+	}
+	return auth.LoadKeySet(signingKeyFile, verificationKeyFiles)
+}
+
+// loadArgon2Params overrides the default argon2id cost with
+// ARGON2_MEMORY_KIB, ARGON2_ITERATIONS and ARGON2_PARALLELISM when set.
+func loadArgon2Params() (auth.Argon2Params, error) {
+	params := auth.DefaultArgon2Params
+	for _, setting := range []struct {
+		env  string
+		bits int
+		set  func(uint64)
+	}{
+		{"ARGON2_MEMORY_KIB", 32, func(v uint64) { params.Memory = uint32(v) }},
+		{"ARGON2_ITERATIONS", 32, func(v uint64) { params.Iterations = uint32(v) }},
+		{"ARGON2_PARALLELISM", 8, func(v uint64) { params.Parallelism = uint8(v) }},
+	} {
+		value := os.Getenv(setting.env)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, setting.bits)
+		if err != nil || n == 0 {
+			return auth.Argon2Params{}, fmt.Errorf("%s must be a positive integer", setting.env)
+		}
+		setting.set(n)
+	}
+	return params, params.Validate()
+}
+
+// newMailer picks the mail transport from MAILER: "smtp" relays through
+// SMTP_HOST, "memory" discards into memory, and anything else writes .eml
+// files to MAIL_DIR, which only dev allows.
+func newMailer(platform string) mail.Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "Chirpy <noreply@localhost>"
+	}
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return mail.NewSMTPMailer(
+			os.Getenv("SMTP_HOST"),
+			port,
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			from,
+		)
+	case "memory":
+		return &mail.MemoryMailer{}
+	}
+	if platform != "dev" {
+		log.Fatal(`MAILER must be "smtp" or "memory" outside of dev`)
+	}
+	// The emails carry reset and verification tokens, so they must not land
+	// anywhere /app serves.
+	dir := os.Getenv("MAIL_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "chirpy-mail")
+	}
+	return &mail.FileMailer{Dir: dir, From: from}
+}
+
+// newBlobStore picks where attachments are kept from BLOB_STORE: "s3" uses
+// the S3-compatible bucket configured by the S3_* variables, and anything
+// else stores files under MEDIA_DIR, which must be set outside of dev.
+func newBlobStore(platform string) media.BlobStore {
+	if os.Getenv("BLOB_STORE") == "s3" {
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &media.S3Store{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    region,
+			AccessKey: os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}
+	}
+	// Attachments are served through the API, so they must not also land
+	// anywhere /app serves.
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" && platform != "dev" {
+		log.Fatal("MEDIA_DIR must be set outside of dev")
+	} else if dir == "" {
+		dir = filepath.Join(os.TempDir(), "chirpy-media")
+	}
+	return &media.FileStore{Dir: dir}
+}
@@ -0,0 +1,771 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"internal/auth"
+	"internal/database"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// maxConversationMembers includes the user starting the conversation.
+	maxConversationMembers = 10
+
+	messageCreatedEvent   = "message.created"
+	messageDeletedEvent   = "message.deleted"
+	conversationReadEvent = "conversation.read"
+)
+
+type conversationResponse struct {
+	ID          uuid.UUID                    `json:"id"`
+	Members     []conversationMemberResponse `json:"members"`
+	LastMessage *messageResponse             `json:"last_message,omitempty"`
+	UnreadCount int64                        `json:"unread_count"`
+	CreatedAt   time.Time                    `json:"created_at"`
+	UpdatedAt   time.Time                    `json:"updated_at"`
+}
+
+type conversationMemberResponse struct {
+	UserID     uuid.UUID `json:"user_id"`
+	LastReadAt time.Time `json:"last_read_at"`
+}
+
+type messageResponse struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+	// ReadBy lists the other members who have read up to this message.
+	ReadBy []uuid.UUID `json:"read_by"`
+}
+
+type conversationsPage struct {
+	Conversations []conversationResponse `json:"conversations"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+}
+
+type messagesPage struct {
+	Messages   []messageResponse `json:"messages"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+type conversationPost struct {
+	// UserIDs are the other members; the authenticated user is added.
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+type messagePost struct {
+	Body string `json:"body"`
+}
+
+type conversationReadRequest struct {
+	// MessageID marks the conversation read up to that message rather than
+	// up to now.
+	MessageID *uuid.UUID `json:"message_id"`
+}
+
+type conversationReadPayload struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	LastReadAt     time.Time `json:"last_read_at"`
+}
+
+type messageDeletedPayload struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+}
+
+type messageSettings struct {
+	// FollowingOnly refuses messages from anyone the user does not follow.
+	FollowingOnly bool `json:"following_only"`
+}
+
+func newMessageResponse(message database.Message, members []database.ConversationMember) messageResponse {
+	response := messageResponse{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		UserID:         message.UserID,
+		Body:           message.Body,
+		CreatedAt:      message.CreatedAt,
+		ReadBy:         []uuid.UUID{},
+	}
+	for _, member := range members {
+		if member.UserID != message.UserID && !member.LastReadAt.Before(message.CreatedAt) {
+			response.ReadBy = append(response.ReadBy, member.UserID)
+		}
+	}
+	return response
+}
+
+// directKey identifies the one-to-one conversation between two users,
+// whichever of them starts it.
+func directKey(a, b uuid.UUID) string {
+	if a.String() > b.String() {
+		a, b = b, a
+	}
+	return a.String() + ":" + b.String()
+}
+
+// publishMessageEvent tells the given conversation members about a message
+// event. Failures are logged; the change itself has already been made.
+func (cfg *apiConfig) publishMessageEvent(ctx context.Context, eventType string, members []database.ConversationMember, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding %s event: %s", eventType, err)
+		return
+	}
+	topics := make([]string, 0, len(members))
+	for _, member := range members {
+		topics = append(topics, messageTopic(member.UserID))
+	}
+	_, err = cfg.events.Publish(ctx, eventType, topics, data)
+	if err != nil {
+		log.Printf("Error publishing %s event: %s", eventType, err)
+	}
+}
+
+// newConversationResponses fills in the members and latest visible message
+// of conversations listed for userID.
+func (cfg *apiConfig) newConversationResponses(ctx context.Context, userID uuid.UUID, conversations []database.GetConversationsRow) ([]conversationResponse, error) {
+	ids := make([]uuid.UUID, 0, len(conversations))
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+	}
+	responses := make([]conversationResponse, 0, len(conversations))
+	if len(ids) == 0 {
+		return responses, nil
+	}
+
+	allMembers, err := cfg.db.GetConversationMembers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	membersByConversation := make(map[uuid.UUID][]database.ConversationMember)
+	for _, member := range allMembers {
+		membersByConversation[member.ConversationID] = append(membersByConversation[member.ConversationID], member)
+	}
+
+	latest, err := cfg.db.GetLatestMessages(ctx, database.GetLatestMessagesParams{
+		ConversationIds: ids,
+		UserID:          userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	latestByConversation := make(map[uuid.UUID]database.Message, len(latest))
+	for _, message := range latest {
+		latestByConversation[message.ConversationID] = message
+	}
+
+	for _, conversation := range conversations {
+		members := membersByConversation[conversation.ID]
+		response := conversationResponse{
+			ID:          conversation.ID,
+			Members:     make([]conversationMemberResponse, 0, len(members)),
+			UnreadCount: conversation.UnreadCount,
+			CreatedAt:   conversation.CreatedAt,
+			UpdatedAt:   conversation.UpdatedAt,
+		}
+		for _, member := range members {
+			response.Members = append(response.Members, conversationMemberResponse{
+				UserID:     member.UserID,
+				LastReadAt: member.LastReadAt,
+			})
+		}
+		if message, ok := latestByConversation[conversation.ID]; ok {
+			lastMessage := newMessageResponse(message, members)
+			response.LastMessage = &lastMessage
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// conversationAccess authenticates a request about a conversation and looks
+// up its members. Conversations the user is not a member of are reported as
+// missing. If it returns false the error response has already been written.
+func (cfg *apiConfig) conversationAccess(w http.ResponseWriter, r *http.Request) (uuid.UUID, []database.ConversationMember, bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return uuid.Nil, nil, false
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return uuid.Nil, nil, false
+	}
+
+	conversationId, err := uuid.Parse(r.PathValue("conversationID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested conversation was not found")
+		return uuid.Nil, nil, false
+	}
+	members, err := cfg.db.GetConversationMembers(r.Context(), []uuid.UUID{conversationId})
+	if err != nil {
+		log.Printf("Error retrieving conversation members: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return uuid.Nil, nil, false
+	}
+	for _, member := range members {
+		if member.UserID == userId {
+			return userId, members, true
+		}
+	}
+	respondWithError(w, 404, "The requested conversation was not found")
+	return uuid.Nil, nil, false
+}
+
+// recipientsAllow reports whether every member other than senderID accepts
+// messages from them, as users can limit messages to people they follow.
+func (cfg *apiConfig) recipientsAllow(ctx context.Context, senderID uuid.UUID, recipientIDs []uuid.UUID) (bool, error) {
+	restricted, err := cfg.db.CountRestrictedRecipients(ctx, database.CountRestrictedRecipientsParams{
+		UserIds:  recipientIDs,
+		SenderID: senderID,
+	})
+	return restricted == 0, err
+}
+
+// handlerCreateConversation starts a conversation between the authenticated
+// user and user_ids. Starting a one-to-one conversation that already exists
+// returns it instead.
+func (cfg *apiConfig) handlerCreateConversation(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	conversationReq := conversationPost{}
+	err = decoder.Decode(&conversationReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if cfg.requireVerifiedEmail && !user.EmailVerifiedAt.Valid {
+		respondWithError(w, 403, "Verify your email address before sending messages")
+		return
+	}
+
+	seen := map[uuid.UUID]bool{userId: true}
+	var recipientIds []uuid.UUID
+	for _, id := range conversationReq.UserIDs {
+		if !seen[id] {
+			seen[id] = true
+			recipientIds = append(recipientIds, id)
+		}
+	}
+	if len(recipientIds) == 0 {
+		respondWithError(w, 400, "A conversation needs at least one other user")
+		return
+	}
+	if len(recipientIds) >= maxConversationMembers {
+		respondWithError(w, 400, fmt.Sprintf("A conversation can have at most %d members", maxConversationMembers))
+		return
+	}
+
+	found, err := cfg.db.CountUsers(r.Context(), recipientIds)
+	if err != nil {
+		log.Printf("Error retrieving users: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if found != int64(len(recipientIds)) {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	}
+	allowed, err := cfg.recipientsAllow(r.Context(), userId, recipientIds)
+	if err != nil {
+		log.Printf("Error checking message settings: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if !allowed {
+		respondWithError(w, 403, "Some of these users only accept messages from people they follow")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	key := sql.NullString{}
+	if len(recipientIds) == 1 {
+		key = sql.NullString{String: directKey(userId, recipientIds[0]), Valid: true}
+		existing, err := qtx.GetDirectConversation(r.Context(), key)
+		if err == nil {
+			cfg.respondWithConversation(w, r, 200, userId, existing.ID)
+			return
+		} else if err != sql.ErrNoRows {
+			log.Printf("Error retrieving conversation: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+	}
+
+	conversation, err := qtx.CreateConversation(r.Context(), key)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		// Both users started the conversation at the same time.
+		respondWithError(w, 409, "The conversation was just started, try again")
+		return
+	} else if err != nil {
+		log.Printf("Error creating conversation: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = qtx.AddConversationMembers(r.Context(), database.AddConversationMembersParams{
+		ConversationID: conversation.ID,
+		UserIds:        append(recipientIds, userId),
+	})
+	if err != nil {
+		log.Printf("Error adding conversation members: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error creating conversation: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	cfg.respondWithConversation(w, r, 201, userId, conversation.ID)
+}
+
+// handlerGetConversations lists the authenticated user's conversations, the
+// most recently active first.
+func (cfg *apiConfig) handlerGetConversations(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	conversations, err := cfg.db.GetConversations(r.Context(), database.GetConversationsParams{
+		UserID:         userId,
+		AfterUpdatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving conversations: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	response := conversationsPage{}
+	if int32(len(conversations)) > page.Limit {
+		conversations = conversations[:page.Limit]
+		last := conversations[len(conversations)-1]
+		response.NextCursor = pageCursor{CreatedAt: last.UpdatedAt, ID: last.ID}.encode()
+	}
+	response.Conversations, err = cfg.newConversationResponses(r.Context(), userId, conversations)
+	if err != nil {
+		log.Printf("Error retrieving conversations: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, response)
+}
+
+func (cfg *apiConfig) handlerGetConversation(w http.ResponseWriter, r *http.Request) {
+	userId, members, ok := cfg.conversationAccess(w, r)
+	if !ok {
+		return
+	}
+	cfg.respondWithConversation(w, r, 200, userId, members[0].ConversationID)
+}
+
+// respondWithConversation writes conversationID as userID sees it.
+func (cfg *apiConfig) respondWithConversation(w http.ResponseWriter, r *http.Request, code int, userID, conversationID uuid.UUID) {
+	conversation, err := cfg.db.GetConversation(r.Context(), database.GetConversationParams{
+		UserID:         userID,
+		ConversationID: conversationID,
+	})
+	if err != nil {
+		log.Printf("Error retrieving conversation: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	responses, err := cfg.newConversationResponses(r.Context(), userID, []database.GetConversationsRow{
+		database.GetConversationsRow(conversation),
+	})
+	if err != nil {
+		log.Printf("Error retrieving conversation: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, code, responses[0])
+}
+
+// handlerGetMessages lists a conversation's messages, newest first, leaving
+// out those the authenticated user deleted for themselves.
+func (cfg *apiConfig) handlerGetMessages(w http.ResponseWriter, r *http.Request) {
+	userId, members, ok := cfg.conversationAccess(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	messages, err := cfg.db.GetMessages(r.Context(), database.GetMessagesParams{
+		ConversationID: members[0].ConversationID,
+		UserID:         userId,
+		AfterCreatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving messages: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	response := messagesPage{Messages: make([]messageResponse, 0, len(messages))}
+	for _, message := range messages {
+		response.Messages = append(response.Messages, newMessageResponse(message, members))
+	}
+	if int32(len(response.Messages)) > page.Limit {
+		response.Messages = response.Messages[:page.Limit]
+		last := response.Messages[len(response.Messages)-1]
+		response.NextCursor = pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}.encode()
+	}
+	respondWithJSON(w, 200, response)
+}
+
+// handlerSendMessage posts a message to a conversation. The body is checked
+// against the sender's length limit and the content filter like a chirp.
+func (cfg *apiConfig) handlerSendMessage(w http.ResponseWriter, r *http.Request) {
+	userId, members, ok := cfg.conversationAccess(w, r)
+	if !ok {
+		return
+	}
+	conversationId := members[0].ConversationID
+
+	decoder := json.NewDecoder(r.Body)
+	messageReq := messagePost{}
+	err := decoder.Decode(&messageReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	sender, err := cfg.db.GetUser(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving user: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if cfg.requireVerifiedEmail && !sender.EmailVerifiedAt.Valid {
+		respondWithError(w, 403, "Verify your email address before sending messages")
+		return
+	}
+
+	// Messages are private, so flagged ones are not queued for moderators;
+	// masking and rejection still apply.
+	filtered, ok := cfg.prepareChirpBody(w, messageReq.Body, cfg.tiers.forUser(sender))
+	if !ok {
+		return
+	}
+
+	var recipientIds []uuid.UUID
+	for _, member := range members {
+		if member.UserID != userId {
+			recipientIds = append(recipientIds, member.UserID)
+		}
+	}
+	allowed, err := cfg.recipientsAllow(r.Context(), userId, recipientIds)
+	if err != nil {
+		log.Printf("Error checking message settings: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if !allowed {
+		respondWithError(w, 403, "Some members of this conversation only accept messages from people they follow")
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	message, err := qtx.CreateMessage(r.Context(), database.CreateMessageParams{
+		ConversationID: conversationId,
+		UserID:         userId,
+		Body:           filtered.Body,
+	})
+	if err != nil {
+		log.Printf("Error creating message: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = qtx.TouchConversation(r.Context(), database.TouchConversationParams{
+		ID:        conversationId,
+		UpdatedAt: message.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("Error updating conversation: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	// Senders have read their own message.
+	_, err = qtx.MarkConversationRead(r.Context(), database.MarkConversationReadParams{
+		ConversationID: conversationId,
+		UserID:         userId,
+		LastReadAt:     sql.NullTime{Time: message.CreatedAt, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error updating conversation: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error creating message: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	response := newMessageResponse(message, members)
+	cfg.publishMessageEvent(r.Context(), messageCreatedEvent, members, response)
+	respondWithJSON(w, 201, response)
+}
+
+// handlerMarkConversationRead records that the authenticated user has read
+// the conversation, which the other members see as read receipts.
+func (cfg *apiConfig) handlerMarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	userId, members, ok := cfg.conversationAccess(w, r)
+	if !ok {
+		return
+	}
+	conversationId := members[0].ConversationID
+
+	decoder := json.NewDecoder(r.Body)
+	readReq := conversationReadRequest{}
+	err := decoder.Decode(&readReq)
+	if err != nil && err != io.EOF {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	// With no message the query uses NOW(), the clock that set created_at.
+	readAt := sql.NullTime{}
+	if readReq.MessageID != nil {
+		message, err := cfg.db.GetMessage(r.Context(), *readReq.MessageID)
+		if err == sql.ErrNoRows || (err == nil && message.ConversationID != conversationId) {
+			respondWithError(w, 404, "The requested message was not found")
+			return
+		} else if err != nil {
+			log.Printf("Error retrieving message: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		readAt = sql.NullTime{Time: message.CreatedAt, Valid: true}
+	}
+
+	lastReadAt, err := cfg.db.MarkConversationRead(r.Context(), database.MarkConversationReadParams{
+		ConversationID: conversationId,
+		UserID:         userId,
+		LastReadAt:     readAt,
+	})
+	if err != nil {
+		log.Printf("Error marking conversation read: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	cfg.publishMessageEvent(r.Context(), conversationReadEvent, members, conversationReadPayload{
+		ConversationID: conversationId,
+		UserID:         userId,
+		LastReadAt:     lastReadAt,
+	})
+	respondWithJSON(w, 204, struct{}{})
+}
+
+// handlerDeleteMessage hides a message from the authenticated user, or with
+// for=everyone removes it from the conversation, which only its sender may
+// do.
+func (cfg *apiConfig) handlerDeleteMessage(w http.ResponseWriter, r *http.Request) {
+	userId, members, ok := cfg.conversationAccess(w, r)
+	if !ok {
+		return
+	}
+
+	messageId, err := uuid.Parse(r.PathValue("messageID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested message was not found")
+		return
+	}
+	message, err := cfg.db.GetMessage(r.Context(), messageId)
+	if err == sql.ErrNoRows || (err == nil && message.ConversationID != members[0].ConversationID) {
+		respondWithError(w, 404, "The requested message was not found")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving message: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	payload := messageDeletedPayload{ID: message.ID, ConversationID: message.ConversationID}
+	if r.URL.Query().Get("for") == "everyone" {
+		if message.UserID != userId {
+			respondWithError(w, 403, "Only the sender can delete a message for everyone")
+			return
+		}
+		err = cfg.db.DeleteMessage(r.Context(), message.ID)
+		if err != nil {
+			log.Printf("Error deleting message: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		cfg.publishMessageEvent(r.Context(), messageDeletedEvent, members, payload)
+		respondWithJSON(w, 204, struct{}{})
+		return
+	}
+
+	err = cfg.db.HideMessage(r.Context(), database.HideMessageParams{
+		MessageID: message.ID,
+		UserID:    userId,
+	})
+	if err != nil {
+		log.Printf("Error deleting message: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	// Only the user's own other sessions need to hear about it.
+	self := []database.ConversationMember{{ConversationID: message.ConversationID, UserID: userId}}
+	cfg.publishMessageEvent(r.Context(), messageDeletedEvent, self, payload)
+	respondWithJSON(w, 204, struct{}{})
+}
+
+func (cfg *apiConfig) handlerGetMessageSettings(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	settings, err := cfg.db.GetMessageSettings(r.Context(), userId)
+	if err == sql.ErrNoRows {
+		respondWithJSON(w, 200, messageSettings{})
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving message settings: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, messageSettings{FollowingOnly: settings.FollowingOnly})
+}
+
+// handlerUpdateMessageSettings changes who may message the authenticated
+// user. Limiting messages to people they follow also applies to existing
+// conversations.
+func (cfg *apiConfig) handlerUpdateMessageSettings(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	settingsReq := messageSettings{}
+	err = decoder.Decode(&settingsReq)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	settings, err := cfg.db.SetMessageSettings(r.Context(), database.SetMessageSettingsParams{
+		UserID:        userId,
+		FollowingOnly: settingsReq.FollowingOnly,
+	})
+	if err != nil {
+		log.Printf("Error updating message settings: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, messageSettings{FollowingOnly: settings.FollowingOnly})
+}
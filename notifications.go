@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"internal/auth"
+	"internal/database"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	notificationMention = "mention"
+	notificationReply   = "reply"
+	notificationLike    = "like"
+	notificationFollow  = "follow"
+
+	// notificationEvent is published on the recipient's notification topic
+	// both for new notifications and for groups that gained an actor, so
+	// clients should replace any notification they hold with the same ID.
+	notificationEvent = "notification"
+)
+
+var notificationTypes = []string{
+	notificationMention,
+	notificationReply,
+	notificationLike,
+	notificationFollow,
+}
+
+type notificationResponse struct {
+	ID      uuid.UUID  `json:"id"`
+	Type    string     `json:"type"`
+	ChirpID *uuid.UUID `json:"chirp_id,omitempty"`
+	// Actors holds the most recent few of ActorCount actors, newest first.
+	Actors     []uuid.UUID `json:"actors"`
+	ActorCount int64       `json:"actor_count"`
+	Summary    string      `json:"summary"`
+	Read       bool        `json:"read"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+type notificationsPage struct {
+	Notifications []notificationResponse `json:"notifications"`
+	UnreadCount   int64                  `json:"unread_count"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
+}
+
+type markNotificationsReadRequest struct {
+	// Before limits marking to notifications last updated at or before it,
+	// so that ones arriving after the client loaded its list stay unread.
+	Before *time.Time `json:"before"`
+}
+
+func newNotificationResponse(notification database.GetNotificationsRow) notificationResponse {
+	response := notificationResponse{
+		ID:         notification.ID,
+		Type:       notification.Type,
+		Actors:     notification.ActorIds,
+		ActorCount: notification.ActorCount,
+		Summary:    notificationSummary(notification.Type, notification.ActorCount),
+		Read:       notification.ReadAt.Valid,
+		CreatedAt:  notification.CreatedAt,
+		UpdatedAt:  notification.UpdatedAt,
+	}
+	if notification.ChirpID.Valid {
+		response.ChirpID = &notification.ChirpID.UUID
+	}
+	if response.Actors == nil {
+		response.Actors = []uuid.UUID{}
+	}
+	return response
+}
+
+// notificationSummary describes a notification for display, such as "5
+// people liked your chirp".
+func notificationSummary(notificationType string, actorCount int64) string {
+	who := "1 person"
+	if actorCount != 1 {
+		who = fmt.Sprintf("%d people", actorCount)
+	}
+	switch notificationType {
+	case notificationMention:
+		return who + " mentioned you in a chirp"
+	case notificationReply:
+		return who + " replied to your chirp"
+	case notificationLike:
+		return who + " liked your chirp"
+	case notificationFollow:
+		return who + " followed you"
+	}
+	return ""
+}
+
+// notify tells userID that actorID mentioned them, replied to or liked their
+// chirp, or followed them. Unread notifications of the same type about the
+// same chirp are grouped, as are unread follows. Nothing is recorded for
+// users acting on their own chirps or who have turned the type off.
+// Failures are logged; the action itself has already been taken.
+func (cfg *apiConfig) notify(ctx context.Context, userID, actorID uuid.UUID, notificationType string, chirpID uuid.NullUUID) {
+	if userID == actorID {
+		return
+	}
+	groupKey := notificationType
+	if chirpID.Valid {
+		groupKey += ":" + chirpID.UUID.String()
+	}
+
+	notificationId, err := cfg.db.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID:   userID,
+		Type:     notificationType,
+		ChirpID:  chirpID,
+		GroupKey: groupKey,
+		ActorID:  actorID,
+	})
+	if err == sql.ErrNoRows {
+		// Turned off, or the actor is already part of the unread group.
+		return
+	} else if err != nil {
+		log.Printf("Error creating notification: %s", err)
+		return
+	}
+
+	notification, err := cfg.db.GetNotification(ctx, notificationId)
+	if err != nil {
+		log.Printf("Error retrieving notification: %s", err)
+		return
+	}
+	data, err := json.Marshal(newNotificationResponse(database.GetNotificationsRow(notification)))
+	if err != nil {
+		log.Printf("Error encoding %s event: %s", notificationEvent, err)
+		return
+	}
+	_, err = cfg.events.Publish(ctx, notificationEvent, []string{notificationTopic(userID)}, data)
+	if err != nil {
+		log.Printf("Error publishing %s event: %s", notificationEvent, err)
+	}
+}
+
+// notifyNewChirp notifies the author of the chirp being replied to and the
+// users mentioned in a newly published chirp. A parent author who is also
+// mentioned is only told about the reply.
+func (cfg *apiConfig) notifyNewChirp(ctx context.Context, chirp database.Chirp, mentioned []uuid.UUID) {
+	var parentAuthorId uuid.UUID
+	if chirp.InReplyTo.Valid {
+		parent, err := cfg.db.GetChirp(ctx, chirp.InReplyTo.UUID)
+		if err != nil {
+			log.Printf("Error retrieving chirp: %s", err)
+		} else {
+			parentAuthorId = parent.UserID
+			cfg.notify(ctx, parent.UserID, chirp.UserID, notificationReply, chirp.InReplyTo)
+		}
+	}
+
+	chirpId := uuid.NullUUID{UUID: chirp.ID, Valid: true}
+	for _, userId := range mentioned {
+		if userId != parentAuthorId {
+			cfg.notify(ctx, userId, chirp.UserID, notificationMention, chirpId)
+		}
+	}
+}
+
+// handlerGetNotifications lists the authenticated user's notifications, most
+// recently updated first, along with how many are unread. unread=true leaves
+// out the ones already read.
+func (cfg *apiConfig) handlerGetNotifications(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	page, err := parsePageParams(query)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	notifications, err := cfg.db.GetNotifications(r.Context(), database.GetNotificationsParams{
+		UserID:         userId,
+		UnreadOnly:     query.Get("unread") == "true",
+		AfterUpdatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving notifications: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	unreadCount, err := cfg.db.CountUnreadNotifications(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error counting unread notifications: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	response := notificationsPage{
+		Notifications: make([]notificationResponse, 0, len(notifications)),
+		UnreadCount:   unreadCount,
+	}
+	for _, notification := range notifications {
+		response.Notifications = append(response.Notifications, newNotificationResponse(notification))
+	}
+	if int32(len(response.Notifications)) > page.Limit {
+		response.Notifications = response.Notifications[:page.Limit]
+		last := response.Notifications[len(response.Notifications)-1]
+		response.NextCursor = pageCursor{CreatedAt: last.UpdatedAt, ID: last.ID}.encode()
+	}
+	respondWithJSON(w, 200, response)
+}
+
+func (cfg *apiConfig) handlerMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	notificationId, err := uuid.Parse(r.PathValue("notificationID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested notification was not found")
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	marked, err := cfg.db.MarkNotificationRead(r.Context(), database.MarkNotificationReadParams{
+		ID:     notificationId,
+		UserID: userId,
+	})
+	if err != nil {
+		log.Printf("Error marking notification read: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	// Someone else's notifications are not visible, so they are reported as
+	// missing rather than forbidden.
+	if marked == 0 {
+		respondWithError(w, 404, "The requested notification was not found")
+		return
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+// handlerMarkNotificationsRead marks all of the authenticated user's
+// notifications read, or those up to an optional "before" time.
+func (cfg *apiConfig) handlerMarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	markReq := markNotificationsReadRequest{}
+	err = decoder.Decode(&markReq)
+	if err != nil && err != io.EOF {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	// With no time given the query uses NOW(), the clock that set updated_at.
+	before := sql.NullTime{}
+	if markReq.Before != nil {
+		before = sql.NullTime{Time: markReq.Before.UTC(), Valid: true}
+	}
+
+	_, err = cfg.db.MarkNotificationsRead(r.Context(), database.MarkNotificationsReadParams{
+		UserID: userId,
+		Before: before,
+	})
+	if err != nil {
+		log.Printf("Error marking notifications read: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+// handlerGetNotificationPreferences reports which notification types the
+// authenticated user receives.
+func (cfg *apiConfig) handlerGetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	preferences, err := notificationPreferences(r.Context(), cfg.db, userId)
+	if err != nil {
+		log.Printf("Error retrieving notification preferences: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, preferences)
+}
+
+// handlerUpdateNotificationPreferences turns notification types on or off,
+// given as an object such as {"like": false}. Types left out are unchanged.
+// Turning a type off does not remove notifications already received.
+func (cfg *apiConfig) handlerUpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	changes := map[string]bool{}
+	err = decoder.Decode(&changes)
+	if err != nil {
+		log.Printf("Error parsing request: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	for notificationType := range changes {
+		if !slices.Contains(notificationTypes, notificationType) {
+			respondWithError(w, 400, fmt.Sprintf("Unknown notification type %q", notificationType))
+			return
+		}
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	for notificationType, enabled := range changes {
+		err = qtx.SetNotificationPreference(r.Context(), database.SetNotificationPreferenceParams{
+			UserID:  userId,
+			Type:    notificationType,
+			Enabled: enabled,
+		})
+		if err != nil {
+			log.Printf("Error updating notification preferences: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+	}
+
+	preferences, err := notificationPreferences(r.Context(), qtx, userId)
+	if err != nil {
+		log.Printf("Error retrieving notification preferences: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error updating notification preferences: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	respondWithJSON(w, 200, preferences)
+}
+
+// notificationPreferences maps every notification type to whether userID
+// receives it. Types without a stored preference are on.
+func notificationPreferences(ctx context.Context, q *database.Queries, userID uuid.UUID) (map[string]bool, error) {
+	stored, err := q.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences := make(map[string]bool, len(notificationTypes))
+	for _, notificationType := range notificationTypes {
+		preferences[notificationType] = true
+	}
+	for _, preference := range stored {
+		preferences[preference.Type] = preference.Enabled
+	}
+	return preferences, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageCursor is the keyset position of the last row on a page. It is handed
+// to clients as an opaque string and only ever compared against
+// (created_at, id) in the database, or (rank, id) for search results
+// ordered by relevance.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+	Rank      *float32
+}
+
+type pageParams struct {
+	Limit  int32
+	Cursor *pageCursor
+}
+
+func (c pageCursor) encode() string {
+	raw := fmt.Sprintf("%d:%s", c.CreatedAt.UnixMicro(), c.ID)
+	if c.Rank != nil {
+		// The exact bits, so the database compares against the same
+		// value it returned.
+		raw += ":" + strconv.FormatUint(uint64(math.Float32bits(*c.Rank)), 10)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	micros, id := parts[0], parts[1]
+	usec, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	cursorID, err := uuid.Parse(id)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	cursor := pageCursor{
+		CreatedAt: time.UnixMicro(usec).UTC(),
+		ID:        cursorID,
+	}
+	if len(parts) == 3 {
+		bits, err := strconv.ParseUint(parts[2], 10, 32)
+		if err != nil {
+			return pageCursor{}, errors.New("malformed cursor")
+		}
+		rank := math.Float32frombits(uint32(bits))
+		cursor.Rank = &rank
+	}
+	return cursor, nil
+}
+
+func parsePageParams(query url.Values) (pageParams, error) {
+	params := pageParams{Limit: defaultPageLimit}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return pageParams{}, errors.New("limit must be a positive integer")
+		}
+		params.Limit = int32(min(n, maxPageLimit))
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return pageParams{}, err
+		}
+		params.Cursor = &c
+	}
+	return params, nil
+}
+
+// afterCreatedAt and afterID are the nullable keyset arguments passed to the
+// paginated queries; both are NULL on the first page.
+func (p pageParams) afterCreatedAt() sql.NullTime {
+	if p.Cursor == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: p.Cursor.CreatedAt, Valid: true}
+}
+
+func (p pageParams) afterID() uuid.NullUUID {
+	if p.Cursor == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: p.Cursor.ID, Valid: true}
+}
+
+// afterRank is the keyset argument for results ordered by search rank. It is
+// NULL on the first page, and when the cursor came from a date ordering.
+func (p pageParams) afterRank() sql.NullFloat64 {
+	if p.Cursor == nil || p.Cursor.Rank == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: float64(*p.Cursor.Rank), Valid: true}
+}
+
+// fetchLimit asks the database for one row more than the page size so we can
+// tell whether another page exists without a separate COUNT.
+func (p pageParams) fetchLimit() int32 {
+	return p.Limit + 1
+}
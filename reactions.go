@@ -0,0 +1,241 @@
+package main
+
+import (
+	"database/sql"
+	"internal/auth"
+	"internal/database"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type likeResponse struct {
+	UserID  uuid.UUID `json:"user_id"`
+	LikedAt time.Time `json:"liked_at"`
+}
+
+type likesPage struct {
+	Likes      []likeResponse `json:"likes"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// reactionTarget authenticates a like or rechirp request and looks up the
+// chirp it is for. If it returns false the error response has already been
+// written.
+func (cfg *apiConfig) reactionTarget(w http.ResponseWriter, r *http.Request) (database.Chirp, uuid.UUID, bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return database.Chirp{}, uuid.Nil, false
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return database.Chirp{}, uuid.Nil, false
+	}
+
+	chirpId, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return database.Chirp{}, uuid.Nil, false
+	}
+	chirp, err := cfg.db.GetChirp(r.Context(), chirpId)
+	if err == sql.ErrNoRows {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return database.Chirp{}, uuid.Nil, false
+	} else if err != nil {
+		log.Printf("Error retrieving chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return database.Chirp{}, uuid.Nil, false
+	}
+	return chirp, userId, true
+}
+
+// handlerLikeChirp likes a chirp for the authenticated user. Liking a chirp
+// twice is not an error and only counts once.
+func (cfg *apiConfig) handlerLikeChirp(w http.ResponseWriter, r *http.Request) {
+	chirp, userId, ok := cfg.reactionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	liked, err := cfg.db.LikeChirp(r.Context(), database.LikeChirpParams{
+		ChirpID: chirp.ID,
+		UserID:  userId,
+	})
+	if err != nil {
+		log.Printf("Error liking chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	if liked > 0 {
+		cfg.notify(r.Context(), chirp.UserID, userId, notificationLike, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(w http.ResponseWriter, r *http.Request) {
+	chirp, userId, ok := cfg.reactionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.UnlikeChirp(r.Context(), database.UnlikeChirpParams{
+		ChirpID: chirp.ID,
+		UserID:  userId,
+	})
+	if err != nil {
+		log.Printf("Error unliking chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+// handlerRechirp shares a chirp into the authenticated user's author feed.
+// Like likes, rechirping twice only counts once.
+func (cfg *apiConfig) handlerRechirp(w http.ResponseWriter, r *http.Request) {
+	chirp, userId, ok := cfg.reactionTarget(w, r)
+	if !ok {
+		return
+	}
+	if chirp.UserID == userId {
+		respondWithError(w, 400, "You cannot rechirp your own chirp")
+		return
+	}
+
+	_, err := cfg.db.CreateRechirp(r.Context(), database.CreateRechirpParams{
+		ChirpID: chirp.ID,
+		UserID:  userId,
+	})
+	if err != nil {
+		log.Printf("Error rechirping chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+func (cfg *apiConfig) handlerUndoRechirp(w http.ResponseWriter, r *http.Request) {
+	chirp, userId, ok := cfg.reactionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.DeleteRechirp(r.Context(), database.DeleteRechirpParams{
+		ChirpID: chirp.ID,
+		UserID:  userId,
+	})
+	if err != nil {
+		log.Printf("Error undoing rechirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+// respondWithAuthorFeed serves GET /api/chirps?author_id=, which interleaves
+// the author's chirps with the ones they rechirped, ordered by when they
+// were posted or rechirped.
+func (cfg *apiConfig) respondWithAuthorFeed(w http.ResponseWriter, r *http.Request, authorId uuid.UUID, sortBy string, page pageParams) {
+	var feed []database.GetAuthorFeedAscRow
+	var err error
+	if sortBy == "desc" {
+		var descFeed []database.GetAuthorFeedDescRow
+		descFeed, err = cfg.db.GetAuthorFeedDesc(r.Context(), database.GetAuthorFeedDescParams{
+			AuthorID:       authorId,
+			AfterCreatedAt: page.afterCreatedAt(),
+			AfterID:        page.afterID(),
+			Limit:          page.fetchLimit(),
+		})
+		for _, item := range descFeed {
+			feed = append(feed, database.GetAuthorFeedAscRow(item))
+		}
+	} else {
+		feed, err = cfg.db.GetAuthorFeedAsc(r.Context(), database.GetAuthorFeedAscParams{
+			AuthorID:       authorId,
+			AfterCreatedAt: page.afterCreatedAt(),
+			AfterID:        page.afterID(),
+			Limit:          page.fetchLimit(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error retrieving chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	response := chirpsPage{Chirps: make([]chirpResponse, 0, len(feed))}
+	if int32(len(feed)) > page.Limit {
+		feed = feed[:page.Limit]
+		last := feed[len(feed)-1]
+		response.NextCursor = pageCursor{CreatedAt: last.FeedAt, ID: last.ID}.encode()
+	}
+	for _, item := range feed {
+		chirp := newChirpResponse(database.Chirp{
+			ID:           item.ID,
+			CreatedAt:    item.CreatedAt,
+			UpdatedAt:    item.UpdatedAt,
+			Body:         item.Body,
+			UserID:       item.UserID,
+			EditCount:    item.EditCount,
+			InReplyTo:    item.InReplyTo,
+			ReplyCount:   item.ReplyCount,
+			LikeCount:    item.LikeCount,
+			RechirpCount: item.RechirpCount,
+		})
+		if item.RechirpedBy.Valid {
+			chirp.RechirpedBy = &item.RechirpedBy.UUID
+		}
+		response.Chirps = append(response.Chirps, chirp)
+	}
+	cfg.loadAttachments(r.Context(), response.Chirps)
+	respondWithJSON(w, 200, response)
+}
+
+// handlerGetChirpLikes lists who liked a chirp, most recent first.
+func (cfg *apiConfig) handlerGetChirpLikes(w http.ResponseWriter, r *http.Request) {
+	chirpId, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	likes, err := cfg.db.GetChirpLikes(r.Context(), database.GetChirpLikesParams{
+		ChirpID:        chirpId,
+		AfterCreatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving likes: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	response := likesPage{Likes: make([]likeResponse, 0, len(likes))}
+	if int32(len(likes)) > page.Limit {
+		likes = likes[:page.Limit]
+		last := likes[len(likes)-1]
+		response.NextCursor = pageCursor{CreatedAt: last.CreatedAt, ID: last.UserID}.encode()
+	}
+	for _, like := range likes {
+		response.Likes = append(response.Likes, likeResponse{UserID: like.UserID, LikedAt: like.CreatedAt})
+	}
+	respondWithJSON(w, 200, response)
+}
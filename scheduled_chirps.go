@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"internal/auth"
+	"internal/database"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const scheduledChirpInterval = 15 * time.Second
+
+type scheduledChirpResponse struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Body      string    `json:"body"`
+	UserID    uuid.UUID `json:"user_id"`
+	PublishAt time.Time `json:"publish_at"`
+}
+
+func newScheduledChirpResponse(chirp database.ScheduledChirp) scheduledChirpResponse {
+	return scheduledChirpResponse{
+		ID:        chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		UpdatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+		UserID:    chirp.UserID,
+		PublishAt: chirp.PublishAt,
+	}
+}
+
+func (cfg *apiConfig) handlerGetScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	chirps, err := cfg.db.GetScheduledChirps(r.Context(), userId)
+	if err != nil {
+		log.Printf("Error retrieving scheduled chirps: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	chirpResponses := make([]scheduledChirpResponse, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpResponses = append(chirpResponses, newScheduledChirpResponse(chirp))
+	}
+	respondWithJSON(w, 200, chirpResponses)
+}
+
+func (cfg *apiConfig) handlerDeleteScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+	if err != nil {
+		log.Printf("Invalid token: %s", err)
+		respondWithError(w, 401, "Unauthorized")
+		return
+	}
+
+	deleted, err := cfg.db.DeleteScheduledChirp(r.Context(), database.DeleteScheduledChirpParams{
+		ID:     chirpID,
+		UserID: userId,
+	})
+	if err != nil {
+		log.Printf("Error deleting scheduled chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+	// Someone else's scheduled chirps are not visible, so they are reported
+	// as missing rather than forbidden.
+	if deleted == 0 {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	}
+
+	respondWithJSON(w, 204, struct{}{})
+}
+
+// publishScheduledChirps periodically moves scheduled chirps that have come
+// due into the public timeline. It runs until ctx is done.
+func (cfg *apiConfig) publishScheduledChirps(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := cfg.publishDueChirps(ctx)
+		if err != nil {
+			log.Printf("Error publishing scheduled chirps: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDueChirps publishes the scheduled chirps that have come due and
+// indexes their tags and mentions in the same transaction, so none goes out
+// without them. If anything fails they all stay scheduled for the next run.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) error {
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	published, err := qtx.PublishScheduledChirps(ctx)
+	if err != nil {
+		return err
+	}
+	mentioned := make([][]uuid.UUID, len(published))
+	for i, chirp := range published {
+		mentioned[i], err = indexChirpEntities(ctx, qtx, chirp)
+		if err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	if len(published) > 0 {
+		log.Printf("Published %d scheduled chirps", len(published))
+	}
+	for i, chirp := range published {
+		cfg.publishChirpEvent(ctx, chirpCreatedEvent, chirp)
+		cfg.notifyNewChirp(ctx, chirp, mentioned[i])
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"database/sql"
+	"internal/chirptext"
+	"internal/database"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type searchResult struct {
+	chirpResponse
+	Rank float32 `json:"rank"`
+	// Snippet is the matching part of the body, HTML escaped, with the
+	// matched words wrapped in <mark> tags.
+	Snippet string `json:"snippet"`
+}
+
+type searchPage struct {
+	Results    []searchResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// handlerSearchChirps serves GET /api/chirps/search. q uses the syntax
+// described on chirptext.SearchQuery; results can be narrowed with
+// author_id and an RFC 3339 since/until range, and are ordered by
+// relevance unless sort=date.
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	tsquery := chirptext.SearchQuery(chirptext.Normalize(query.Get("q")))
+	if tsquery == "" {
+		respondWithError(w, 400, "q must contain something to search for")
+		return
+	}
+
+	authorId := uuid.NullUUID{}
+	if authorParam := query.Get("author_id"); authorParam != "" {
+		id, err := uuid.Parse(authorParam)
+		if err != nil {
+			respondWithError(w, 400, "Invalid author_id")
+			return
+		}
+		authorId = uuid.NullUUID{UUID: id, Valid: true}
+	}
+	since, err := parseTimeParam(query.Get("since"))
+	if err != nil {
+		respondWithError(w, 400, "since must be an RFC 3339 timestamp")
+		return
+	}
+	until, err := parseTimeParam(query.Get("until"))
+	if err != nil {
+		respondWithError(w, 400, "until must be an RFC 3339 timestamp")
+		return
+	}
+
+	page, err := parsePageParams(query)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+	byDate := query.Get("sort") == "date"
+	if page.Cursor != nil && byDate != (page.Cursor.Rank == nil) {
+		respondWithError(w, 400, "cursor does not match sort")
+		return
+	}
+
+	var rows []database.SearchChirpsByRankRow
+	if byDate {
+		var dateRows []database.SearchChirpsByDateRow
+		dateRows, err = cfg.db.SearchChirpsByDate(r.Context(), database.SearchChirpsByDateParams{
+			Query:          tsquery,
+			AuthorID:       authorId,
+			Since:          since,
+			Until:          until,
+			AfterCreatedAt: page.afterCreatedAt(),
+			AfterID:        page.afterID(),
+			Limit:          page.fetchLimit(),
+		})
+		for _, row := range dateRows {
+			rows = append(rows, database.SearchChirpsByRankRow(row))
+		}
+	} else {
+		rows, err = cfg.db.SearchChirpsByRank(r.Context(), database.SearchChirpsByRankParams{
+			Query:     tsquery,
+			AuthorID:  authorId,
+			Since:     since,
+			Until:     until,
+			AfterRank: page.afterRank(),
+			AfterID:   page.afterID(),
+			Limit:     page.fetchLimit(),
+		})
+	}
+	if err != nil {
+		log.Printf("Error searching chirps: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	response := searchPage{Results: make([]searchResult, 0, len(rows))}
+	if int32(len(rows)) > page.Limit {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		cursor := pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if !byDate {
+			cursor.Rank = &last.Rank
+		}
+		response.NextCursor = cursor.encode()
+	}
+	for _, row := range rows {
+		response.Results = append(response.Results, searchResult{
+			chirpResponse: newChirpResponse(database.Chirp{
+				ID:           row.ID,
+				CreatedAt:    row.CreatedAt,
+				UpdatedAt:    row.UpdatedAt,
+				Body:         row.Body,
+				UserID:       row.UserID,
+				EditCount:    row.EditCount,
+				InReplyTo:    row.InReplyTo,
+				ReplyCount:   row.ReplyCount,
+				LikeCount:    row.LikeCount,
+				RechirpCount: row.RechirpCount,
+			}),
+			Rank:    row.Rank,
+			Snippet: row.Snippet,
+		})
+	}
+	ids := make([]uuid.UUID, 0, len(response.Results))
+	for _, result := range response.Results {
+		ids = append(ids, result.ID)
+	}
+	attachments := cfg.chirpAttachments(r.Context(), ids...)
+	for i := range response.Results {
+		response.Results[i].Attachments = attachments[response.Results[i].ID]
+	}
+	respondWithJSON(w, 200, response)
+}
+
+func parseTimeParam(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}, nil
+}
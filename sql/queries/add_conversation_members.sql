@@ -0,0 +1,5 @@
+-- name: AddConversationMembers :exec
+INSERT INTO conversation_members (conversation_id, user_id, joined_at, last_read_at)
+SELECT sqlc.arg('conversation_id'), user_id, NOW(), NOW()
+FROM   unnest(sqlc.arg('user_ids')::uuid[]) AS user_id
+ON CONFLICT (conversation_id, user_id) DO NOTHING;
\ No newline at end of file
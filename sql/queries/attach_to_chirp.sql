@@ -0,0 +1,8 @@
+-- name: AttachToChirp :execrows
+UPDATE attachments
+SET    chirp_id = sqlc.arg('chirp_id'),
+       position = array_position(sqlc.arg('ids')::uuid[], id)
+WHERE  id = ANY(sqlc.arg('ids')::uuid[])
+   AND user_id = sqlc.arg('user_id')
+   AND chirp_id IS NULL
+   AND created_at > NOW() - make_interval(secs => sqlc.arg('ttl_seconds')::float8);
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- name: ClaimPolkaEvent :one
+UPDATE    polka_events
+SET       status = 'processing',
+          updated_at = NOW()
+WHERE     id = sqlc.arg('id')
+      AND (status = ANY(sqlc.arg('statuses')::text[])
+           OR (status = 'processing' AND updated_at < NOW() - INTERVAL '5 minutes'))
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: ClearLoginThrottle :exec
+DELETE
+FROM    login_throttles
+WHERE   key = $1;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- name: ComputeTrendingTags :exec
+INSERT INTO trending_tags (tag, chirp_count, computed_at)
+SELECT    tag, COUNT(*), NOW()
+FROM      chirp_tags
+WHERE     created_at > NOW() - make_interval(secs => sqlc.arg('window_seconds')::float8)
+GROUP BY  tag
+ORDER BY  COUNT(*) DESC, tag ASC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
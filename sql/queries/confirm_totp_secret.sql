@@ -0,0 +1,7 @@
+-- name: ConfirmTotpSecret :execrows
+UPDATE    totp_secrets
+SET       confirmed_at = NOW(),
+          last_used_step = $2,
+          updated_at = NOW()
+WHERE     user_id = $1
+      AND confirmed_at IS NULL;
\ No newline at end of file
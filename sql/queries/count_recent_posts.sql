@@ -0,0 +1,5 @@
+-- name: CountRecentPosts :one
+SELECT (
+    (SELECT COUNT(*) FROM chirps WHERE user_id = $1 AND created_at > NOW() - INTERVAL '1 hour')
+    + (SELECT COUNT(*) FROM scheduled_chirps WHERE user_id = $1 AND created_at > NOW() - INTERVAL '1 hour')
+)::bigint AS post_count;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- name: CountRestrictedRecipients :one
+SELECT COUNT(*) AS restricted_count
+FROM   message_settings
+WHERE  user_id = ANY(sqlc.arg('user_ids')::uuid[])
+   AND following_only
+   AND NOT EXISTS (
+           SELECT 1
+           FROM   follows
+           WHERE  follower_id = message_settings.user_id
+              AND followee_id = sqlc.arg('sender_id')
+       );
\ No newline at end of file
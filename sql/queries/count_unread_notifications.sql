@@ -0,0 +1,5 @@
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) AS unread_count
+FROM   notifications
+WHERE  user_id = $1
+   AND read_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: CountUsers :one
+SELECT COUNT(*) AS user_count
+FROM   users
+WHERE  id = ANY(sqlc.arg('ids')::uuid[]);
\ No newline at end of file
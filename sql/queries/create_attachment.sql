@@ -0,0 +1,26 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (
+    id,
+    created_at,
+    user_id,
+    content_type,
+    size_bytes,
+    width,
+    height,
+    thumbnail_content_type,
+    thumbnail_width,
+    thumbnail_height
+)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: CreateChirpMentions :many
+INSERT INTO chirp_mentions (chirp_id, user_id, created_at)
+SELECT sqlc.arg('chirp_id'), id, sqlc.arg('created_at')
+FROM   users
+WHERE  lower(handle) = ANY(sqlc.arg('handles')::text[])
+ON CONFLICT (chirp_id, user_id) DO NOTHING
+RETURNING user_id;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- name: CreateChirpReview :exec
+INSERT INTO chirp_reviews (chirp_id, created_at, matched_words)
+VALUES (
+    $1,
+    NOW(),
+    $2
+)
+ON CONFLICT (chirp_id) DO NOTHING;
\ No newline at end of file
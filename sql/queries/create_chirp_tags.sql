@@ -0,0 +1,5 @@
+-- name: CreateChirpTags :exec
+INSERT INTO chirp_tags (chirp_id, tag, created_at)
+SELECT sqlc.arg('chirp_id'), tag, sqlc.arg('created_at')
+FROM   unnest(sqlc.arg('tags')::text[]) AS tag
+ON CONFLICT (chirp_id, tag) DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- name: CreateConversation :one
+INSERT INTO conversations (id, created_at, updated_at, direct_key)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1
+)
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (token_hash, created_at, user_id, email, expires_at, used_at)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    NOW() + INTERVAL '24 HOUR',
+    NULL
+);
\ No newline at end of file
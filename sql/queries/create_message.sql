@@ -0,0 +1,10 @@
+-- name: CreateMessage :one
+INSERT INTO messages (id, created_at, conversation_id, user_id, body)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- name: CreateNotification :one
+WITH notification AS (
+    INSERT INTO notifications (id, user_id, type, chirp_id, group_key, created_at, updated_at)
+    SELECT gen_random_uuid(), sqlc.arg('user_id'), sqlc.arg('type'), sqlc.narg('chirp_id'), sqlc.arg('group_key'), NOW(), NOW()
+    WHERE  NOT EXISTS (
+               SELECT 1
+               FROM   notification_preferences
+               WHERE  user_id = sqlc.arg('user_id')
+                  AND type = sqlc.arg('type')
+                  AND NOT enabled
+           )
+    ON CONFLICT (user_id, group_key) WHERE read_at IS NULL
+    DO UPDATE SET updated_at = NOW()
+    RETURNING id
+)
+INSERT INTO notification_actors (notification_id, actor_id, created_at)
+SELECT id, sqlc.arg('actor_id'), NOW()
+FROM   notification
+ON CONFLICT (notification_id, actor_id) DO NOTHING
+RETURNING notification_id;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, created_at, user_id, expires_at, used_at)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    NOW() + INTERVAL '1 HOUR',
+    NULL
+);
\ No newline at end of file
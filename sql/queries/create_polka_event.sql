@@ -0,0 +1,15 @@
+-- name: CreatePolkaEvent :one
+INSERT INTO polka_events (id, created_at, updated_at, event, payload, status, last_error, attempts, processed_at)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    $2,
+    $3,
+    'received',
+    NULL,
+    0,
+    NULL
+)
+ON CONFLICT (id) DO NOTHING
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: CreateRechirp :execrows
+WITH rechirped AS (
+    INSERT INTO rechirps (chirp_id, user_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT (chirp_id, user_id) DO NOTHING
+    RETURNING chirp_id
+)
+UPDATE  chirps
+SET     rechirp_count = rechirp_count + 1
+WHERE   id IN (SELECT chirp_id FROM rechirped);
\ No newline at end of file
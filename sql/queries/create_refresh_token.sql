@@ -1,11 +1,13 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at)
+INSERT INTO refresh_tokens (token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, parent_token_hash)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
     NOW() + INTERVAL '60 DAY',
-    NULL
+    NULL,
+    $3,
+    $4
 )
 RETURNING *;
\ No newline at end of file
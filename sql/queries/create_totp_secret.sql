@@ -0,0 +1,16 @@
+-- name: CreateTotpSecret :one
+INSERT INTO totp_secrets (user_id, created_at, updated_at, secret, confirmed_at, last_used_step)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    $2,
+    NULL,
+    0
+)
+ON CONFLICT (user_id) DO UPDATE
+SET       secret = EXCLUDED.secret,
+          updated_at = NOW(),
+          last_used_step = 0
+WHERE     totp_secrets.confirmed_at IS NULL
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- name: DecrementReplyCount :exec
+UPDATE  chirps
+SET     reply_count = reply_count - 1
+WHERE   id = $1
+    AND reply_count > 0;
\ No newline at end of file
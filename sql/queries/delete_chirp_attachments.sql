@@ -0,0 +1,4 @@
+-- name: DeleteChirpAttachments :many
+DELETE FROM attachments
+WHERE  chirp_id = $1
+RETURNING id;
\ No newline at end of file
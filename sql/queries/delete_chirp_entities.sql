@@ -0,0 +1,6 @@
+-- name: DeleteChirpEntities :exec
+WITH deleted_tags AS (
+    DELETE FROM chirp_tags WHERE chirp_id = $1
+)
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- name: DeleteChirpReview :execrows
+DELETE FROM chirp_reviews
+WHERE chirp_id = $1;
\ No newline at end of file
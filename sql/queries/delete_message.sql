@@ -0,0 +1,3 @@
+-- name: DeleteMessage :exec
+DELETE FROM messages
+WHERE id = $1;
\ No newline at end of file
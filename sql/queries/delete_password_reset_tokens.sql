@@ -0,0 +1,4 @@
+-- name: DeletePasswordResetTokens :exec
+DELETE
+FROM    password_reset_tokens
+WHERE   user_id = $1;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: DeleteRechirp :execrows
+WITH unrechirped AS (
+    DELETE FROM rechirps
+    WHERE       chirp_id = $1
+            AND user_id = $2
+    RETURNING   chirp_id
+)
+UPDATE  chirps
+SET     rechirp_count = rechirp_count - 1
+WHERE   id IN (SELECT chirp_id FROM unrechirped);
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: DeleteRecoveryCodes :exec
+DELETE
+FROM    recovery_codes
+WHERE   user_id = $1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: DeleteScheduledChirp :execrows
+DELETE FROM scheduled_chirps
+WHERE id = $1
+  AND user_id = $2;
\ No newline at end of file
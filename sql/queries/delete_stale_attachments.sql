@@ -0,0 +1,5 @@
+-- name: DeleteStaleAttachments :many
+DELETE FROM attachments
+WHERE  chirp_id IS NULL
+   AND created_at < NOW() - make_interval(secs => sqlc.arg('ttl_seconds')::float8)
+RETURNING id;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- name: DeleteTrendingTags :exec
+DELETE FROM trending_tags;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: DowngradeUser :one
+UPDATE  users
+SET     is_chirpy_red = false,
+        updated_at = NOW()
+WHERE   id = $1
+RETURNING *;
\ No newline at end of file
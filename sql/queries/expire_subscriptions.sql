@@ -0,0 +1,13 @@
+-- name: ExpireSubscriptions :execrows
+WITH expired AS (
+    UPDATE    subscriptions
+    SET       status = 'expired',
+              updated_at = NOW()
+    WHERE     status IN ('active', 'past_due')
+          AND current_period_end < NOW()
+    RETURNING user_id
+)
+UPDATE  users
+SET     is_chirpy_red = false,
+        updated_at = NOW()
+WHERE   id IN (SELECT user_id FROM expired);
\ No newline at end of file
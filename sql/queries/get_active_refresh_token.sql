@@ -1,12 +1,14 @@
 -- name: GetActiveRefreshToken :one
 SELECT
-          token
+          token_hash
           ,created_at
           ,updated_at
           ,user_id
           ,expires_at
           ,revoked_at
+          ,family_id
+          ,parent_token_hash
 FROM      refresh_tokens
-WHERE     token = $1
+WHERE     token_hash = $1
       AND NOW() < expires_at
       AND revoked_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,16 @@
+-- name: GetAttachment :one
+SELECT
+          id
+          ,created_at
+          ,user_id
+          ,chirp_id
+          ,position
+          ,content_type
+          ,size_bytes
+          ,width
+          ,height
+          ,thumbnail_content_type
+          ,thumbnail_width
+          ,thumbnail_height
+FROM      attachments
+WHERE     id = $1;
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- name: GetAuthorFeedAsc :many
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,body
+          ,user_id
+          ,edit_count
+          ,in_reply_to
+          ,reply_count
+          ,like_count
+          ,rechirp_count
+          ,rechirped_by
+          ,feed_at
+FROM      (
+              SELECT    id, created_at, updated_at, body, user_id, edit_count, in_reply_to, reply_count, like_count, rechirp_count,
+                        NULL::uuid AS rechirped_by, created_at AS feed_at
+              FROM      chirps
+              WHERE     user_id = sqlc.arg('author_id')
+              UNION ALL
+              SELECT    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.edit_count, chirps.in_reply_to, chirps.reply_count, chirps.like_count, chirps.rechirp_count,
+                        rechirps.user_id, rechirps.created_at
+              FROM      rechirps
+              JOIN      chirps ON chirps.id = rechirps.chirp_id
+              WHERE     rechirps.user_id = sqlc.arg('author_id')
+          ) feed
+WHERE     sqlc.narg('after_created_at')::timestamp IS NULL
+       OR (feed_at, id) > (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+ORDER BY  feed_at ASC, id ASC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
@@ -5,5 +5,10 @@ SELECT
           ,updated_at
           ,body
           ,user_id
+          ,edit_count
+          ,in_reply_to
+          ,reply_count
+          ,like_count
+          ,rechirp_count
 FROM      chirps
 WHERE     id = $1;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT    parent.id, parent.created_at, parent.updated_at, parent.body, parent.user_id, parent.edit_count, parent.in_reply_to, parent.reply_count, parent.like_count, parent.rechirp_count, 1 AS depth
+    FROM      chirps parent
+    JOIN      chirps child ON child.in_reply_to = parent.id
+    WHERE     child.id = $1
+    UNION ALL
+    SELECT    parent.id, parent.created_at, parent.updated_at, parent.body, parent.user_id, parent.edit_count, parent.in_reply_to, parent.reply_count, parent.like_count, parent.rechirp_count, ancestors.depth + 1
+    FROM      chirps parent
+    JOIN      ancestors ON ancestors.in_reply_to = parent.id
+)
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,body
+          ,user_id
+          ,edit_count
+          ,in_reply_to
+          ,reply_count
+          ,like_count
+          ,rechirp_count
+FROM      ancestors
+ORDER BY  depth DESC;
\ No newline at end of file
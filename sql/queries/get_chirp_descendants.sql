@@ -0,0 +1,26 @@
+-- name: GetChirpDescendants :many
+WITH RECURSIVE descendants AS (
+    SELECT    reply.id, reply.created_at, reply.updated_at, reply.body, reply.user_id, reply.edit_count, reply.in_reply_to, reply.reply_count, reply.like_count, reply.rechirp_count
+    FROM      chirps reply
+    WHERE     reply.in_reply_to = sqlc.arg('id')
+    UNION ALL
+    SELECT    reply.id, reply.created_at, reply.updated_at, reply.body, reply.user_id, reply.edit_count, reply.in_reply_to, reply.reply_count, reply.like_count, reply.rechirp_count
+    FROM      chirps reply
+    JOIN      descendants ON reply.in_reply_to = descendants.id
+)
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,body
+          ,user_id
+          ,edit_count
+          ,in_reply_to
+          ,reply_count
+          ,like_count
+          ,rechirp_count
+FROM      descendants
+WHERE     sqlc.narg('after_created_at')::timestamp IS NULL
+       OR (created_at, id) > (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+ORDER BY  created_at ASC, id ASC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- name: GetChirpForUpdate :one
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,body
+          ,user_id
+          ,edit_count
+          ,in_reply_to
+          ,reply_count
+          ,like_count
+          ,rechirp_count
+FROM      chirps
+WHERE     id = $1
+FOR UPDATE;
\ No newline at end of file
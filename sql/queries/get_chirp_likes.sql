@@ -0,0 +1,10 @@
+-- name: GetChirpLikes :many
+SELECT
+          user_id
+          ,created_at
+FROM      likes
+WHERE     chirp_id = sqlc.arg('chirp_id')
+      AND (sqlc.narg('after_created_at')::timestamp IS NULL
+           OR (created_at, user_id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY  created_at DESC, user_id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
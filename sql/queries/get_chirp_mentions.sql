@@ -0,0 +1,4 @@
+-- name: GetChirpMentions :many
+SELECT user_id
+FROM   chirp_mentions
+WHERE  chirp_id = $1;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- name: GetChirpReviews :many
+SELECT
+          chirp_reviews.chirp_id
+          ,chirp_reviews.created_at
+          ,chirp_reviews.matched_words
+          ,chirps.body
+          ,chirps.user_id
+FROM      chirp_reviews
+JOIN      chirps ON chirps.id = chirp_reviews.chirp_id
+ORDER BY  chirp_reviews.created_at ASC
+LIMIT     $1;
\ No newline at end of file
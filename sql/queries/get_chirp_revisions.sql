@@ -0,0 +1,10 @@
+-- name: GetChirpRevisions :many
+SELECT
+          id
+          ,chirp_id
+          ,created_at
+          ,revision
+          ,body
+FROM      chirp_revisions
+WHERE     chirp_id = $1
+ORDER BY  revision ASC;
\ No newline at end of file
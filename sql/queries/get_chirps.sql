@@ -1,9 +0,0 @@
--- name: GetChirps :many
-SELECT
-          id
-          ,created_at
-          ,updated_at
-          ,body
-          ,user_id
-FROM      chirps
-ORDER BY  created_at ASC;
\ No newline at end of file
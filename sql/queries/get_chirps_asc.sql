@@ -0,0 +1,18 @@
+-- name: GetChirpsAsc :many
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,body
+          ,user_id
+          ,edit_count
+          ,in_reply_to
+          ,reply_count
+          ,like_count
+          ,rechirp_count
+FROM      chirps
+WHERE     (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id')::uuid)
+      AND (sqlc.narg('after_created_at')::timestamp IS NULL
+           OR (created_at, id) > (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY  created_at ASC, id ASC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
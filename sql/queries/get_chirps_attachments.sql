@@ -0,0 +1,17 @@
+-- name: GetChirpsAttachments :many
+SELECT
+          id
+          ,created_at
+          ,user_id
+          ,chirp_id
+          ,position
+          ,content_type
+          ,size_bytes
+          ,width
+          ,height
+          ,thumbnail_content_type
+          ,thumbnail_width
+          ,thumbnail_height
+FROM      attachments
+WHERE     chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY  chirp_id, position;
\ No newline at end of file
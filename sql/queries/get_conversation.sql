@@ -0,0 +1,23 @@
+-- name: GetConversation :one
+SELECT
+          conversations.id
+          ,conversations.created_at
+          ,conversations.updated_at
+          ,conversations.direct_key
+          ,(
+              SELECT  COUNT(*)
+              FROM    messages
+              WHERE   messages.conversation_id = conversations.id
+                  AND messages.user_id <> conversation_members.user_id
+                  AND messages.created_at > conversation_members.last_read_at
+                  AND NOT EXISTS (
+                          SELECT 1
+                          FROM   message_deletions
+                          WHERE  message_deletions.message_id = messages.id
+                             AND message_deletions.user_id = conversation_members.user_id
+                      )
+          ) AS unread_count
+FROM      conversation_members
+JOIN      conversations ON conversations.id = conversation_members.conversation_id
+WHERE     conversation_members.user_id = sqlc.arg('user_id')
+      AND conversations.id = sqlc.arg('conversation_id');
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- name: GetConversationMembers :many
+SELECT
+          conversation_id
+          ,user_id
+          ,joined_at
+          ,last_read_at
+FROM      conversation_members
+WHERE     conversation_id = ANY(sqlc.arg('conversation_ids')::uuid[])
+ORDER BY  conversation_id, joined_at, user_id;
\ No newline at end of file
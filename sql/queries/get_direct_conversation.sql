@@ -0,0 +1,8 @@
+-- name: GetDirectConversation :one
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,direct_key
+FROM      conversations
+WHERE     direct_key = $1;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: GetFollowCounts :one
+SELECT
+          (SELECT COUNT(*) FROM follows WHERE followee_id = sqlc.arg('user_id')) AS follower_count
+          ,(SELECT COUNT(*) FROM follows WHERE follower_id = sqlc.arg('user_id')) AS following_count;
\ No newline at end of file
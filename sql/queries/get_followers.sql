@@ -0,0 +1,10 @@
+-- name: GetFollowers :many
+SELECT
+          follower_id AS user_id
+          ,created_at
+FROM      follows
+WHERE     followee_id = sqlc.arg('user_id')
+      AND (sqlc.narg('after_created_at')::timestamp IS NULL
+           OR (created_at, follower_id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY  created_at DESC, follower_id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: GetFollowing :many
+SELECT
+          followee_id AS user_id
+          ,created_at
+FROM      follows
+WHERE     follower_id = sqlc.arg('user_id')
+      AND (sqlc.narg('after_created_at')::timestamp IS NULL
+           OR (created_at, followee_id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY  created_at DESC, followee_id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: GetFollowingIDs :many
+SELECT    followee_id
+FROM      follows
+WHERE     follower_id = $1;
\ No newline at end of file
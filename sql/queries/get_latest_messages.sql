@@ -0,0 +1,16 @@
+-- name: GetLatestMessages :many
+SELECT DISTINCT ON (messages.conversation_id)
+          messages.id
+          ,messages.created_at
+          ,messages.conversation_id
+          ,messages.user_id
+          ,messages.body
+FROM      messages
+WHERE     messages.conversation_id = ANY(sqlc.arg('conversation_ids')::uuid[])
+      AND NOT EXISTS (
+              SELECT 1
+              FROM   message_deletions
+              WHERE  message_deletions.message_id = messages.id
+                 AND message_deletions.user_id = sqlc.arg('user_id')
+          )
+ORDER BY  messages.conversation_id, messages.created_at DESC, messages.id DESC;
\ No newline at end of file
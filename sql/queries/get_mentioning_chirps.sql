@@ -0,0 +1,19 @@
+-- name: GetMentioningChirps :many
+SELECT
+          chirps.id
+          ,chirps.created_at
+          ,chirps.updated_at
+          ,chirps.body
+          ,chirps.user_id
+          ,chirps.edit_count
+          ,chirps.in_reply_to
+          ,chirps.reply_count
+          ,chirps.like_count
+          ,chirps.rechirp_count
+FROM      chirp_mentions
+JOIN      chirps ON chirps.id = chirp_mentions.chirp_id
+WHERE     chirp_mentions.user_id = sqlc.arg('user_id')
+      AND (sqlc.narg('after_created_at')::timestamp IS NULL
+           OR (chirp_mentions.created_at, chirp_mentions.chirp_id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY  chirp_mentions.created_at DESC, chirp_mentions.chirp_id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
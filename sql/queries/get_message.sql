@@ -0,0 +1,9 @@
+-- name: GetMessage :one
+SELECT
+          id
+          ,created_at
+          ,conversation_id
+          ,user_id
+          ,body
+FROM      messages
+WHERE     id = $1;
\ No newline at end of file
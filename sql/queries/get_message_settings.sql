@@ -0,0 +1,6 @@
+-- name: GetMessageSettings :one
+SELECT
+          user_id
+          ,following_only
+FROM      message_settings
+WHERE     user_id = $1;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- name: GetMessages :many
+SELECT
+          messages.id
+          ,messages.created_at
+          ,messages.conversation_id
+          ,messages.user_id
+          ,messages.body
+FROM      messages
+WHERE     messages.conversation_id = sqlc.arg('conversation_id')
+      AND NOT EXISTS (
+              SELECT 1
+              FROM   message_deletions
+              WHERE  message_deletions.message_id = messages.id
+                 AND message_deletions.user_id = sqlc.arg('user_id')
+          )
+      AND (sqlc.narg('after_created_at')::timestamp IS NULL
+           OR (messages.created_at, messages.id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY  messages.created_at DESC, messages.id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- name: GetNotification :one
+SELECT
+          notifications.id
+          ,notifications.type
+          ,notifications.chirp_id
+          ,notifications.created_at
+          ,notifications.updated_at
+          ,notifications.read_at
+          ,actors.actor_count
+          ,actors.actor_ids
+FROM      notifications
+CROSS JOIN LATERAL (
+    SELECT  COUNT(*) AS actor_count
+            ,(array_agg(actor_id ORDER BY created_at DESC))[1:3]::uuid[] AS actor_ids
+    FROM    notification_actors
+    WHERE   notification_id = notifications.id
+) actors
+WHERE     notifications.id = $1;
\ No newline at end of file
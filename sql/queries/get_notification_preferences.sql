@@ -0,0 +1,4 @@
+-- name: GetNotificationPreferences :many
+SELECT user_id, type, enabled
+FROM   notification_preferences
+WHERE  user_id = $1;
\ No newline at end of file
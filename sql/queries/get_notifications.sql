@@ -0,0 +1,23 @@
+-- name: GetNotifications :many
+SELECT
+          notifications.id
+          ,notifications.type
+          ,notifications.chirp_id
+          ,notifications.created_at
+          ,notifications.updated_at
+          ,notifications.read_at
+          ,actors.actor_count
+          ,actors.actor_ids
+FROM      notifications
+CROSS JOIN LATERAL (
+    SELECT  COUNT(*) AS actor_count
+            ,(array_agg(actor_id ORDER BY created_at DESC))[1:3]::uuid[] AS actor_ids
+    FROM    notification_actors
+    WHERE   notification_id = notifications.id
+) actors
+WHERE     notifications.user_id = sqlc.arg('user_id')
+      AND (NOT sqlc.arg('unread_only')::boolean OR notifications.read_at IS NULL)
+      AND (sqlc.narg('after_updated_at')::timestamp IS NULL
+           OR (notifications.updated_at, notifications.id) < (sqlc.narg('after_updated_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY  notifications.updated_at DESC, notifications.id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
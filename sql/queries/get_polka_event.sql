@@ -0,0 +1,13 @@
+-- name: GetPolkaEvent :one
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,event
+          ,payload
+          ,status
+          ,last_error
+          ,attempts
+          ,processed_at
+FROM      polka_events
+WHERE     id = $1;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- name: GetRefreshToken :one
+SELECT
+          token_hash
+          ,created_at
+          ,updated_at
+          ,user_id
+          ,expires_at
+          ,revoked_at
+          ,family_id
+          ,parent_token_hash
+FROM      refresh_tokens
+WHERE     token_hash = $1;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- name: GetScheduledChirps :many
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,body
+          ,user_id
+          ,publish_at
+FROM      scheduled_chirps
+WHERE     user_id = $1
+ORDER BY  publish_at ASC, id ASC;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- name: GetSubscription :one
+SELECT
+          user_id
+          ,created_at
+          ,updated_at
+          ,status
+          ,current_period_start
+          ,current_period_end
+          ,canceled_at
+FROM      subscriptions
+WHERE     user_id = $1;
\ No newline at end of file
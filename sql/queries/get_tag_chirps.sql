@@ -0,0 +1,19 @@
+-- name: GetTagChirps :many
+SELECT
+          chirps.id
+          ,chirps.created_at
+          ,chirps.updated_at
+          ,chirps.body
+          ,chirps.user_id
+          ,chirps.edit_count
+          ,chirps.in_reply_to
+          ,chirps.reply_count
+          ,chirps.like_count
+          ,chirps.rechirp_count
+FROM      chirp_tags
+JOIN      chirps ON chirps.id = chirp_tags.chirp_id
+WHERE     chirp_tags.tag = sqlc.arg('tag')
+      AND (sqlc.narg('after_created_at')::timestamp IS NULL
+           OR (chirp_tags.created_at, chirp_tags.chirp_id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+ORDER BY  chirp_tags.created_at DESC, chirp_tags.chirp_id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
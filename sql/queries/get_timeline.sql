@@ -0,0 +1,28 @@
+-- name: GetTimeline :many
+SELECT
+          timeline.id
+          ,timeline.created_at
+          ,timeline.updated_at
+          ,timeline.body
+          ,timeline.user_id
+          ,timeline.edit_count
+          ,timeline.in_reply_to
+          ,timeline.reply_count
+          ,timeline.like_count
+          ,timeline.rechirp_count
+FROM      (
+              SELECT followee_id AS author_id FROM follows WHERE follower_id = sqlc.arg('user_id')
+              UNION ALL
+              SELECT sqlc.arg('user_id')::uuid
+          ) authors
+CROSS JOIN LATERAL (
+              SELECT    id, created_at, updated_at, body, user_id, edit_count, in_reply_to, reply_count, like_count, rechirp_count
+              FROM      chirps
+              WHERE     user_id = authors.author_id
+                    AND (sqlc.narg('after_created_at')::timestamp IS NULL
+                         OR (created_at, id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid))
+              ORDER BY  created_at DESC, id DESC
+              LIMIT     sqlc.arg('limit')
+          ) timeline
+ORDER BY  timeline.created_at DESC, timeline.id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: GetTotpSecret :one
+SELECT
+          user_id
+          ,created_at
+          ,updated_at
+          ,secret
+          ,confirmed_at
+          ,last_used_step
+FROM      totp_secrets
+WHERE     user_id = $1;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- name: GetTrendingTags :many
+SELECT
+          tag
+          ,chirp_count
+          ,computed_at
+FROM      trending_tags
+ORDER BY  chirp_count DESC, tag ASC
+LIMIT     $1;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- name: GetUser :one
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,email
+          ,hashed_password
+          ,is_chirpy_red
+          ,email_verified_at
+          ,handle
+FROM      users
+WHERE     id = $1;
\ No newline at end of file
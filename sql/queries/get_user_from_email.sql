@@ -6,5 +6,7 @@ SELECT
           ,email
           ,hashed_password
           ,is_chirpy_red
+          ,email_verified_at
+          ,handle
 FROM      users
 WHERE     email = $1;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: HideMessage :exec
+INSERT INTO message_deletions (message_id, user_id)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT (message_id, user_id) DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: IncrementReplyCount :execrows
+UPDATE  chirps
+SET     reply_count = reply_count + 1
+WHERE   id = $1;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: LikeChirp :execrows
+WITH liked AS (
+    INSERT INTO likes (chirp_id, user_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT (chirp_id, user_id) DO NOTHING
+    RETURNING chirp_id
+)
+UPDATE  chirps
+SET     like_count = like_count + 1
+WHERE   id IN (SELECT chirp_id FROM liked);
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- name: ListPolkaEvents :many
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,event
+          ,payload
+          ,status
+          ,last_error
+          ,attempts
+          ,processed_at
+FROM      polka_events
+WHERE     (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+ORDER BY  created_at DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
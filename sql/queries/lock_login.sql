@@ -0,0 +1,4 @@
+-- name: LockLogin :exec
+UPDATE    login_throttles
+SET       locked_until = NOW() + make_interval(secs => sqlc.arg('lockout_seconds')::float8)
+WHERE     key = sqlc.arg('key');
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- name: LockUser :exec
+SELECT    id
+FROM      users
+WHERE     id = $1
+FOR UPDATE;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: MarkConversationRead :one
+UPDATE  conversation_members
+SET     last_read_at = GREATEST(last_read_at, COALESCE(sqlc.narg('last_read_at')::timestamp, NOW()))
+WHERE   conversation_id = sqlc.arg('conversation_id')
+    AND user_id = sqlc.arg('user_id')
+RETURNING last_read_at;
\ No newline at end of file
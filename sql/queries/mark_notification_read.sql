@@ -0,0 +1,5 @@
+-- name: MarkNotificationRead :execrows
+UPDATE  notifications
+SET     read_at = COALESCE(read_at, NOW())
+WHERE   id = $1
+    AND user_id = $2;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: MarkNotificationsRead :execrows
+UPDATE  notifications
+SET     read_at = NOW()
+WHERE   user_id = sqlc.arg('user_id')
+    AND read_at IS NULL
+    AND updated_at <= COALESCE(sqlc.narg('before')::timestamp, NOW());
\ No newline at end of file
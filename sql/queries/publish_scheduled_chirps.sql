@@ -0,0 +1,10 @@
+-- name: PublishScheduledChirps :many
+WITH due AS (
+    DELETE FROM scheduled_chirps
+    WHERE       publish_at <= NOW()
+    RETURNING   id, body, user_id
+)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+SELECT id, NOW(), NOW(), body, user_id
+FROM   due
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: ReleaseLoginAttempt :exec
+UPDATE    login_throttles
+SET       failures = GREATEST(failures - 1, 0)
+WHERE     key = $1;
\ No newline at end of file
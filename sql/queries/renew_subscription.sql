@@ -0,0 +1,9 @@
+-- name: RenewSubscription :one
+UPDATE    subscriptions
+SET       status = 'active',
+          current_period_start = COALESCE(sqlc.narg('period_start')::timestamp, GREATEST(current_period_end, NOW())),
+          current_period_end = COALESCE(sqlc.narg('period_end')::timestamp, GREATEST(current_period_end, NOW()) + INTERVAL '1 MONTH'),
+          canceled_at = NULL,
+          updated_at = NOW()
+WHERE     user_id = sqlc.arg('user_id')
+RETURNING *;
\ No newline at end of file
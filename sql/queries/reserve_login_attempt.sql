@@ -0,0 +1,16 @@
+-- name: ReserveLoginAttempt :one
+INSERT INTO login_throttles (key, failures, last_failure_at, locked_until)
+VALUES (
+    $1,
+    1,
+    NOW(),
+    NULL
+)
+ON CONFLICT (key) DO UPDATE
+SET       failures = CASE
+              WHEN login_throttles.last_failure_at < NOW() - INTERVAL '1 DAY' THEN 1
+              ELSE login_throttles.failures + 1
+          END,
+          last_failure_at = NOW()
+RETURNING failures,
+          GREATEST(COALESCE(CEIL(EXTRACT(EPOCH FROM (locked_until - NOW()))), 0), 0)::int AS retry_after_seconds;
\ No newline at end of file
@@ -2,4 +2,4 @@
 UPDATE    refresh_tokens
 SET       revoked_at = NOW(),
           updated_at = NOW()
-WHERE     token = $1;
\ No newline at end of file
+WHERE     token_hash = $1;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- name: RotateRefreshToken :one
+UPDATE    refresh_tokens
+SET       revoked_at = NOW(),
+          updated_at = NOW()
+WHERE     token_hash = $1
+      AND NOW() < expires_at
+      AND revoked_at IS NULL
+RETURNING *;
\ No newline at end of file
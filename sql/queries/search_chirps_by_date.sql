@@ -0,0 +1,27 @@
+-- name: SearchChirpsByDate :many
+SELECT
+          id
+          ,created_at
+          ,updated_at
+          ,body
+          ,user_id
+          ,edit_count
+          ,in_reply_to
+          ,reply_count
+          ,like_count
+          ,rechirp_count
+          ,rank
+          ,ts_headline('english', replace(replace(replace(replace(replace(body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&apos;'), query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')::text AS snippet
+FROM      (
+              SELECT    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.edit_count, chirps.in_reply_to, chirps.reply_count, chirps.like_count, chirps.rechirp_count,
+                        ts_rank(to_tsvector('english', chirps.body), query)::real AS rank, query
+              FROM      chirps, to_tsquery('english', sqlc.arg('query')) query
+              WHERE     to_tsvector('english', chirps.body) @@ query
+                    AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id')::uuid)
+                    AND (sqlc.narg('since')::timestamp IS NULL OR chirps.created_at >= sqlc.narg('since')::timestamp)
+                    AND (sqlc.narg('until')::timestamp IS NULL OR chirps.created_at < sqlc.narg('until')::timestamp)
+          ) matches
+WHERE     sqlc.narg('after_created_at')::timestamp IS NULL
+       OR (created_at, id) < (sqlc.narg('after_created_at')::timestamp, sqlc.narg('after_id')::uuid)
+ORDER BY  created_at DESC, id DESC
+LIMIT     sqlc.arg('limit');
\ No newline at end of file
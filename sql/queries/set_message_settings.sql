@@ -0,0 +1,8 @@
+-- name: SetMessageSettings :one
+INSERT INTO message_settings (user_id, following_only)
+VALUES (
+    $1,
+    $2
+)
+ON CONFLICT (user_id) DO UPDATE SET following_only = EXCLUDED.following_only
+RETURNING *;
\ No newline at end of file
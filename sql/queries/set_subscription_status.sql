@@ -0,0 +1,7 @@
+-- name: SetSubscriptionStatus :one
+UPDATE    subscriptions
+SET       status = sqlc.arg('status'),
+          canceled_at = CASE WHEN sqlc.arg('status') = 'canceled' THEN NOW() ELSE canceled_at END,
+          updated_at = NOW()
+WHERE     user_id = sqlc.arg('user_id')
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- name: StartSubscription :one
+INSERT INTO subscriptions (user_id, created_at, updated_at, status, current_period_start, current_period_end, canceled_at)
+VALUES (
+    sqlc.arg('user_id'),
+    NOW(),
+    NOW(),
+    'active',
+    COALESCE(sqlc.narg('period_start')::timestamp, NOW()),
+    COALESCE(sqlc.narg('period_end')::timestamp, NOW() + INTERVAL '1 MONTH'),
+    NULL
+)
+ON CONFLICT (user_id) DO UPDATE
+SET       status = EXCLUDED.status,
+          current_period_start = EXCLUDED.current_period_start,
+          current_period_end = EXCLUDED.current_period_end,
+          canceled_at = NULL,
+          updated_at = NOW()
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- name: TouchConversation :exec
+UPDATE  conversations
+SET     updated_at = $2
+WHERE   id = $1;
\ No newline at end of file
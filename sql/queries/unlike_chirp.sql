@@ -0,0 +1,10 @@
+-- name: UnlikeChirp :execrows
+WITH unliked AS (
+    DELETE FROM likes
+    WHERE       chirp_id = $1
+            AND user_id = $2
+    RETURNING   chirp_id
+)
+UPDATE  chirps
+SET     like_count = like_count - 1
+WHERE   id IN (SELECT chirp_id FROM unliked);
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: UpdateChirp :one
+UPDATE    chirps
+SET       body = $1,
+          edit_count = edit_count + 1,
+          updated_at = NOW()
+WHERE     id = $2
+RETURNING *;
\ No newline at end of file
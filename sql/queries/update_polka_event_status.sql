@@ -0,0 +1,9 @@
+-- name: UpdatePolkaEventStatus :one
+UPDATE    polka_events
+SET       status = sqlc.arg('status'),
+          last_error = sqlc.narg('last_error'),
+          attempts = attempts + 1,
+          processed_at = CASE WHEN sqlc.arg('status') = 'processed' THEN NOW() ELSE processed_at END,
+          updated_at = NOW()
+WHERE     id = sqlc.arg('id')
+RETURNING *;
\ No newline at end of file
@@ -2,6 +2,7 @@
 UPDATE  users
 SET     email = $1,
         hashed_password = $2,
+        email_verified_at = CASE WHEN email = $1 THEN email_verified_at ELSE NULL END,
         updated_at = NOW()
 WHERE   id = $3
 RETURNING *;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: UpdateUserPassword :one
+UPDATE  users
+SET     hashed_password = $2,
+        updated_at = NOW()
+WHERE   id = $1
+RETURNING *;
\ No newline at end of file
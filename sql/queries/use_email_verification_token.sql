@@ -0,0 +1,7 @@
+-- name: UseEmailVerificationToken :one
+UPDATE    email_verification_tokens
+SET       used_at = NOW()
+WHERE     token_hash = $1
+      AND NOW() < expires_at
+      AND used_at IS NULL
+RETURNING user_id, email;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: UsePasswordResetToken :one
+UPDATE    password_reset_tokens
+SET       used_at = NOW()
+WHERE     token_hash = $1
+      AND NOW() < expires_at
+      AND used_at IS NULL
+RETURNING user_id;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: UseRecoveryCode :execrows
+UPDATE    recovery_codes
+SET       used_at = NOW()
+WHERE     user_id = $1
+      AND code_hash = $2
+      AND used_at IS NULL;
\ No newline at end of file
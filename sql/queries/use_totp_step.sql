@@ -0,0 +1,7 @@
+-- name: UseTotpStep :execrows
+UPDATE    totp_secrets
+SET       last_used_step = $2,
+          updated_at = NOW()
+WHERE     user_id = $1
+      AND confirmed_at IS NOT NULL
+      AND last_used_step < $2;
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: VerifyUserEmail :one
+UPDATE  users
+SET     email_verified_at = NOW(),
+        updated_at = NOW()
+WHERE   id = $1
+    AND email = $2
+RETURNING *;
\ No newline at end of file
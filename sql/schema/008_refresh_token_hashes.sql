@@ -0,0 +1,17 @@
+-- +goose Up
+-- Existing rows hold plaintext tokens that cannot be rehashed without the
+-- server pepper, so every outstanding session is invalidated.
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+RENAME COLUMN token TO token_hash;
+
+ALTER TABLE refresh_tokens
+RENAME COLUMN parent_token TO parent_token_hash;
+
+-- +goose Down
+ALTER TABLE refresh_tokens
+RENAME COLUMN parent_token_hash TO parent_token;
+
+ALTER TABLE refresh_tokens
+RENAME COLUMN token_hash TO token;
\ No newline at end of file
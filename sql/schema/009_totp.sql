@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE totp_secrets (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  secret TEXT NOT NULL,
+  confirmed_at TIMESTAMP NULL,
+  last_used_step BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE recovery_codes (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  code_hash TEXT NOT NULL,
+  used_at TIMESTAMP NULL,
+  UNIQUE (user_id, code_hash)
+);
+
+-- +goose Down
+DROP TABLE recovery_codes;
+DROP TABLE totp_secrets;
\ No newline at end of file
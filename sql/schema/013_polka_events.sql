@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE polka_events (
+  id TEXT PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  event TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  status TEXT NOT NULL,
+  last_error TEXT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  processed_at TIMESTAMP NULL
+);
+
+CREATE INDEX polka_events_created_at_idx ON polka_events (created_at);
+
+-- +goose Down
+DROP TABLE polka_events;
\ No newline at end of file
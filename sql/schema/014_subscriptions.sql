@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE subscriptions (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  status TEXT NOT NULL,
+  current_period_start TIMESTAMP NOT NULL,
+  current_period_end TIMESTAMP NOT NULL,
+  canceled_at TIMESTAMP NULL
+);
+
+CREATE INDEX subscriptions_current_period_end_idx ON subscriptions (current_period_end)
+WHERE status IN ('active', 'past_due');
+
+-- Users upgraded before subscriptions were tracked get a period starting now.
+INSERT INTO subscriptions (user_id, created_at, updated_at, status, current_period_start, current_period_end)
+SELECT id, NOW(), NOW(), 'active', NOW(), NOW() + INTERVAL '1 MONTH'
+FROM   users
+WHERE  is_chirpy_red;
+
+-- +goose Down
+DROP TABLE subscriptions;
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- +goose Up
+-- No foreign key to chirps: scheduled chirps are filtered, and may be
+-- flagged, before their row in chirps exists.
+CREATE TABLE chirp_reviews (
+  chirp_id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  matched_words TEXT[] NOT NULL
+);
+
+-- +goose Down
+DROP TABLE chirp_reviews;
\ No newline at end of file
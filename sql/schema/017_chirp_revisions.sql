@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN edit_count INTEGER NOT NULL DEFAULT 0;
+
+-- Each row is a body a chirp used to have: revision 0 is the original, and
+-- created_at is when that body was written.
+CREATE TABLE chirp_revisions (
+  id UUID PRIMARY KEY,
+  chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  revision INTEGER NOT NULL,
+  body TEXT NOT NULL,
+  UNIQUE (chirp_id, revision)
+);
+
+-- +goose Down
+DROP TABLE chirp_revisions;
+
+ALTER TABLE chirps
+DROP COLUMN edit_count;
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN rechirp_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE likes (
+  chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (chirp_id, user_id)
+);
+
+CREATE INDEX likes_chirp_id_created_at_idx ON likes (chirp_id, created_at, user_id);
+
+CREATE TABLE rechirps (
+  chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (chirp_id, user_id)
+);
+
+CREATE INDEX rechirps_user_id_created_at_idx ON rechirps (user_id, created_at, chirp_id);
+
+-- +goose Down
+DROP TABLE rechirps;
+DROP TABLE likes;
+
+ALTER TABLE chirps
+DROP COLUMN rechirp_count,
+DROP COLUMN like_count;
\ No newline at end of file
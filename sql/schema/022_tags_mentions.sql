@@ -0,0 +1,42 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN handle TEXT NULL;
+
+CREATE UNIQUE INDEX users_handle_idx ON users (lower(handle));
+
+-- created_at is copied from the chirp so a tag can be browsed, and trends
+-- computed, without touching chirps.
+CREATE TABLE chirp_tags (
+  chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE NOT NULL,
+  tag TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (chirp_id, tag)
+);
+
+CREATE INDEX chirp_tags_tag_created_at_idx ON chirp_tags (tag, created_at, chirp_id);
+CREATE INDEX chirp_tags_created_at_idx ON chirp_tags (created_at);
+
+CREATE TABLE chirp_mentions (
+  chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (chirp_id, user_id)
+);
+
+CREATE INDEX chirp_mentions_user_id_created_at_idx ON chirp_mentions (user_id, created_at, chirp_id);
+
+-- Rebuilt periodically by the trending aggregator.
+CREATE TABLE trending_tags (
+  tag TEXT PRIMARY KEY,
+  chirp_count INTEGER NOT NULL,
+  computed_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE trending_tags;
+DROP TABLE chirp_mentions;
+DROP TABLE chirp_tags;
+
+DROP INDEX users_handle_idx;
+ALTER TABLE users
+DROP COLUMN handle;
\ No newline at end of file
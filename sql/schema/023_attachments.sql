@@ -0,0 +1,24 @@
+-- +goose Up
+-- Uploads start out unattached and are claimed by the chirp they are posted
+-- with. Attachments that stay unattached, or whose chirp is deleted, are
+-- swept up along with their blobs after a while.
+CREATE TABLE attachments (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+  position INTEGER,
+  content_type TEXT NOT NULL,
+  size_bytes INTEGER NOT NULL,
+  width INTEGER NOT NULL,
+  height INTEGER NOT NULL,
+  thumbnail_content_type TEXT NOT NULL,
+  thumbnail_width INTEGER NOT NULL,
+  thumbnail_height INTEGER NOT NULL
+);
+
+CREATE INDEX attachments_chirp_id_position_idx ON attachments (chirp_id, position);
+CREATE INDEX attachments_unattached_created_at_idx ON attachments (created_at) WHERE chirp_id IS NULL;
+
+-- +goose Down
+DROP TABLE attachments;
\ No newline at end of file
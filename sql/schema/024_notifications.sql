@@ -0,0 +1,38 @@
+-- +goose Up
+-- Repeated events of the same kind, such as likes on one chirp, are grouped
+-- into a single notification while it is unread: each actor is recorded once
+-- in notification_actors and the notification moves back to the top. Once
+-- read, the next event starts a new group.
+CREATE TABLE notifications (
+  id UUID PRIMARY KEY,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  type TEXT NOT NULL,
+  chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE,
+  group_key TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  read_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX notifications_unread_group_key_idx ON notifications (user_id, group_key) WHERE read_at IS NULL;
+CREATE INDEX notifications_user_id_updated_at_idx ON notifications (user_id, updated_at, id);
+
+CREATE TABLE notification_actors (
+  notification_id UUID REFERENCES notifications(id) ON DELETE CASCADE NOT NULL,
+  actor_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  created_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (notification_id, actor_id)
+);
+
+-- Only the types a user has changed are stored; the rest are enabled.
+CREATE TABLE notification_preferences (
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  type TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL,
+  PRIMARY KEY (user_id, type)
+);
+
+-- +goose Down
+DROP TABLE notification_preferences;
+DROP TABLE notification_actors;
+DROP TABLE notifications;
\ No newline at end of file
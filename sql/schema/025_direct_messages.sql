@@ -0,0 +1,52 @@
+-- +goose Up
+-- A conversation is between two or more members. One-to-one conversations
+-- have a direct_key made from both user IDs, so each pair has only one.
+CREATE TABLE conversations (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  direct_key TEXT UNIQUE
+);
+
+-- Messages up to last_read_at have been read by the member, which is what
+-- read receipts are worked out from.
+CREATE TABLE conversation_members (
+  conversation_id UUID REFERENCES conversations(id) ON DELETE CASCADE NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  joined_at TIMESTAMP NOT NULL,
+  last_read_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE INDEX conversation_members_user_id_idx ON conversation_members (user_id);
+
+-- Deleting a message for everyone removes it; deleting it for yourself only
+-- hides it, by recording a row in message_deletions.
+CREATE TABLE messages (
+  id UUID PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL,
+  conversation_id UUID REFERENCES conversations(id) ON DELETE CASCADE NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  body TEXT NOT NULL
+);
+
+CREATE INDEX messages_conversation_id_created_at_idx ON messages (conversation_id, created_at, id);
+
+CREATE TABLE message_deletions (
+  message_id UUID REFERENCES messages(id) ON DELETE CASCADE NOT NULL,
+  user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+  PRIMARY KEY (message_id, user_id)
+);
+
+-- Users without a row accept messages from anyone.
+CREATE TABLE message_settings (
+  user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  following_only BOOLEAN NOT NULL
+);
+
+-- +goose Down
+DROP TABLE message_settings;
+DROP TABLE message_deletions;
+DROP TABLE messages;
+DROP TABLE conversation_members;
+DROP TABLE conversations;
\ No newline at end of file
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"internal/auth"
+	"internal/chirptext"
+	"internal/database"
+	"internal/stream"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	chirpCreatedEvent = "chirp.created"
+	chirpUpdatedEvent = "chirp.updated"
+	chirpDeletedEvent = "chirp.deleted"
+	// streamResetEvent tells a resuming client that events it missed are
+	// no longer retained, so it should reload instead.
+	streamResetEvent = "reset"
+
+	streamHistorySize       = 1000
+	streamBufferSize        = 256
+	streamHeartbeatInterval = 15 * time.Second
+)
+
+type chirpDeletedPayload struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+func userTopic(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+func tagTopic(tag string) string {
+	return "tag:" + tag
+}
+
+// notificationTopic carries a user's own notifications, which only they
+// may subscribe to.
+func notificationTopic(userID uuid.UUID) string {
+	return "notifications:" + userID.String()
+}
+
+// messageTopic carries the direct message events of a user's conversations,
+// which only they may subscribe to.
+func messageTopic(userID uuid.UUID) string {
+	return "messages:" + userID.String()
+}
+
+// publishChirpEvent tells stream subscribers that chirp was created, edited
+// or deleted. The event is tagged with the author's topic and one per
+// hashtag. Failures are logged; the change itself has already been made.
+func (cfg *apiConfig) publishChirpEvent(ctx context.Context, eventType string, chirp database.Chirp) {
+	var payload any = chirpDeletedPayload{ID: chirp.ID, UserID: chirp.UserID}
+	if eventType != chirpDeletedEvent {
+		payload = cfg.newChirpResponseWithAttachments(ctx, chirp)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding %s event: %s", eventType, err)
+		return
+	}
+
+	topics := []string{userTopic(chirp.UserID)}
+	for _, tag := range chirptext.ExtractTags(chirp.Body) {
+		topics = append(topics, tagTopic(tag))
+	}
+	_, err = cfg.events.Publish(ctx, eventType, topics, data)
+	if err != nil {
+		log.Printf("Error publishing %s event: %s", eventType, err)
+	}
+}
+
+// handlerStream pushes chirp events as Server-Sent Events. author_id limits
+// the stream to one author, like it does on GET /api/chirps, and
+// timeline=true to the authenticated user's home timeline. A client
+// reconnecting with Last-Event-ID first receives the events it missed.
+func (cfg *apiConfig) handlerStream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var match func(stream.Event) bool
+
+	if authorParam := query.Get("author_id"); authorParam != "" {
+		authorId, err := uuid.Parse(authorParam)
+		if err != nil {
+			respondWithError(w, 400, "Invalid author_id")
+			return
+		}
+		topic := userTopic(authorId)
+		match = func(event stream.Event) bool {
+			return event.HasTopic(topic)
+		}
+	}
+
+	if query.Get("timeline") == "true" {
+		if match != nil {
+			respondWithError(w, 400, "Use either author_id or timeline")
+			return
+		}
+		token, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			log.Printf("Invalid token: %s", err)
+			respondWithError(w, 401, "Unauthorized")
+			return
+		}
+		userId, err := auth.ValidateJWT(token, cfg.jwtKeys)
+		if err != nil {
+			log.Printf("Invalid token: %s", err)
+			respondWithError(w, 401, "Unauthorized")
+			return
+		}
+		topics, err := cfg.timelineTopics(r.Context(), userId)
+		if err != nil {
+			log.Printf("Error retrieving followed users: %s", err)
+			respondWithError(w, 500, "Something went wrong")
+			return
+		}
+		// Follows made while the stream is open are picked up when the
+		// client reconnects.
+		match = func(event stream.Event) bool {
+			for _, topic := range event.Topics {
+				if topics[topic] {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	// EventSource sends Last-Event-ID itself when it reconnects; the query
+	// parameter is for clients resuming a new connection.
+	lastEventParam := r.Header.Get("Last-Event-ID")
+	if lastEventParam == "" {
+		lastEventParam = query.Get("last_event_id")
+	}
+	var lastEventId uint64
+	if lastEventParam != "" {
+		id, err := strconv.ParseUint(lastEventParam, 10, 64)
+		if err != nil {
+			respondWithError(w, 400, "Invalid Last-Event-ID")
+			return
+		}
+		lastEventId = id
+	}
+
+	sub := cfg.events.Subscribe(lastEventId, match)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(200)
+
+	fmt.Fprintf(w, "retry: %d\n\n", 3000)
+	if sub.Missed {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", streamResetEvent)
+	}
+	for _, event := range sub.Replay {
+		writeStreamEvent(w, event)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind; the client reconnects
+				// with Last-Event-ID and catches up from the history.
+				log.Printf("Closing event stream: %s", sub.Err())
+				return
+			}
+			writeStreamEvent(w, event)
+		case <-heartbeat.C:
+			io.WriteString(w, ": heartbeat\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// timelineTopics is the set of topics on userID's home timeline: their own
+// chirps and those of everyone they follow.
+func (cfg *apiConfig) timelineTopics(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	following, err := cfg.db.GetFollowingIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	topics := make(map[string]bool, len(following)+1)
+	topics[userTopic(userID)] = true
+	for _, followeeId := range following {
+		topics[userTopic(followeeId)] = true
+	}
+	return topics, nil
+}
+
+func writeStreamEvent(w io.Writer, event stream.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"internal/database"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A Chirpy Red subscription is "active" while paid up, "past_due" after a
+// failed payment until its period lapses, "canceled" once downgraded and
+// "expired" when the period ran out without a renewal.
+const (
+	subscriptionActive   = "active"
+	subscriptionPastDue  = "past_due"
+	subscriptionCanceled = "canceled"
+	subscriptionExpired  = "expired"
+)
+
+const subscriptionExpiryInterval = time.Minute
+
+type subscriptionResponse struct {
+	Status             string     `json:"status"`
+	CurrentPeriodStart time.Time  `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time  `json:"current_period_end"`
+	CanceledAt         *time.Time `json:"canceled_at"`
+}
+
+func newSubscriptionResponse(subscription database.Subscription) *subscriptionResponse {
+	response := &subscriptionResponse{
+		Status:             subscription.Status,
+		CurrentPeriodStart: subscription.CurrentPeriodStart,
+		CurrentPeriodEnd:   subscription.CurrentPeriodEnd,
+	}
+	if subscription.CanceledAt.Valid {
+		response.CanceledAt = &subscription.CanceledAt.Time
+	}
+	return response
+}
+
+// userSubscription returns the subscription to show on a User response, or
+// nil if the user has never subscribed. Lookup errors are logged rather than
+// failing the request the subscription is attached to.
+func (cfg *apiConfig) userSubscription(ctx context.Context, userID uuid.UUID) *subscriptionResponse {
+	subscription, err := cfg.db.GetSubscription(ctx, userID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		log.Printf("Error retrieving subscription: %s", err)
+		return nil
+	}
+	return newSubscriptionResponse(subscription)
+}
+
+// expireSubscriptions periodically takes Chirpy Red away from users whose
+// subscription period has lapsed without a renewal. It runs until ctx is
+// done.
+func (cfg *apiConfig) expireSubscriptions(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		expired, err := cfg.db.ExpireSubscriptions(ctx)
+		if err != nil {
+			log.Printf("Error expiring subscriptions: %s", err)
+		} else if expired > 0 {
+			log.Printf("Expired %d Chirpy Red subscriptions", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"internal/chirptext"
+	"internal/database"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// trendingWindow is how far back the aggregator looks when ranking
+	// hashtags, and trendingInterval how often it recomputes them.
+	trendingWindow       = 24 * time.Hour
+	trendingInterval     = 5 * time.Minute
+	trendingTagsStored   = 100
+	defaultTrendingLimit = 10
+)
+
+type trendingTagResponse struct {
+	Tag        string    `json:"tag"`
+	ChirpCount int32     `json:"chirp_count"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// indexChirpEntities replaces the hashtags and mentions recorded for chirp
+// with those in its current body, and returns the users it mentions.
+// Mentions of handles that do not belong to anyone are dropped.
+func indexChirpEntities(ctx context.Context, q *database.Queries, chirp database.Chirp) ([]uuid.UUID, error) {
+	err := q.DeleteChirpEntities(ctx, chirp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tags := chirptext.ExtractTags(chirp.Body); len(tags) > 0 {
+		err = q.CreateChirpTags(ctx, database.CreateChirpTagsParams{
+			ChirpID:   chirp.ID,
+			CreatedAt: chirp.CreatedAt,
+			Tags:      tags,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	handles := chirptext.ExtractMentions(chirp.Body)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+	return q.CreateChirpMentions(ctx, database.CreateChirpMentionsParams{
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		Handles:   handles,
+	})
+}
+
+// handlerGetTagChirps lists the chirps carrying a hashtag, newest first. The
+// tag may be given with or without its leading #.
+func (cfg *apiConfig) handlerGetTagChirps(w http.ResponseWriter, r *http.Request) {
+	tag := strings.ToLower(chirptext.Normalize(strings.TrimPrefix(r.PathValue("tag"), "#")))
+	if tag == "" {
+		respondWithError(w, 404, "The requested tag was not found")
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	tagged, err := cfg.db.GetTagChirps(r.Context(), database.GetTagChirpsParams{
+		Tag:            tag,
+		AfterCreatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving tagged chirps: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	dbChirps := make([]database.Chirp, 0, len(tagged))
+	for _, chirp := range tagged {
+		dbChirps = append(dbChirps, database.Chirp(chirp))
+	}
+	response := newChirpsPage(dbChirps, page.Limit)
+	cfg.loadAttachments(r.Context(), response.Chirps)
+	respondWithJSON(w, 200, response)
+}
+
+// handlerGetUserMentions lists the chirps that mention userID, newest first.
+func (cfg *apiConfig) handlerGetUserMentions(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested user was not found")
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	mentioning, err := cfg.db.GetMentioningChirps(r.Context(), database.GetMentioningChirpsParams{
+		UserID:         userId,
+		AfterCreatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving mentions: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	dbChirps := make([]database.Chirp, 0, len(mentioning))
+	for _, chirp := range mentioning {
+		dbChirps = append(dbChirps, database.Chirp(chirp))
+	}
+	response := newChirpsPage(dbChirps, page.Limit)
+	cfg.loadAttachments(r.Context(), response.Chirps)
+	respondWithJSON(w, 200, response)
+}
+
+// handlerGetTrending returns the most used hashtags as of the aggregator's
+// last run.
+func (cfg *apiConfig) handlerGetTrending(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTrendingLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 {
+			respondWithError(w, 400, "limit must be a positive integer")
+			return
+		}
+		limit = min(n, trendingTagsStored)
+	}
+
+	trending, err := cfg.db.GetTrendingTags(r.Context(), int32(limit))
+	if err != nil {
+		log.Printf("Error retrieving trending tags: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	response := make([]trendingTagResponse, 0, len(trending))
+	for _, tag := range trending {
+		response = append(response, trendingTagResponse{
+			Tag:        tag.Tag,
+			ChirpCount: tag.ChirpCount,
+			ComputedAt: tag.ComputedAt,
+		})
+	}
+	respondWithJSON(w, 200, response)
+}
+
+// aggregateTrendingTags periodically recounts hashtag use over the trending
+// window into trending_tags, so GET /api/trending is a small read. It runs
+// until ctx is done.
+func (cfg *apiConfig) aggregateTrendingTags(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := cfg.refreshTrendingTags(ctx)
+		if err != nil {
+			log.Printf("Error computing trending tags: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshTrendingTags swaps in a fresh ranking in one transaction so readers
+// never see an empty table.
+func (cfg *apiConfig) refreshTrendingTags(ctx context.Context) error {
+	tx, err := cfg.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.DeleteTrendingTags(ctx)
+	if err != nil {
+		return err
+	}
+	err = qtx.ComputeTrendingTags(ctx, database.ComputeTrendingTagsParams{
+		WindowSeconds: trendingWindow.Seconds(),
+		Limit:         trendingTagsStored,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"database/sql"
+	"internal/database"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// chirpThread is a chirp in the context of its conversation: the chain of
+// chirps it replies to, root first, and a page of every reply beneath it
+// in the order they were posted. Each reply's in_reply_to places it in the
+// tree.
+type chirpThread struct {
+	Ancestors  []chirpResponse `json:"ancestors"`
+	Chirp      chirpResponse   `json:"chirp"`
+	Replies    []chirpResponse `json:"replies"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerGetChirpThread(w http.ResponseWriter, r *http.Request) {
+	chirpId, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	}
+
+	page, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(r.Context(), chirpId)
+	if err == sql.ErrNoRows {
+		respondWithError(w, 404, "The requested chirp was not found")
+		return
+	} else if err != nil {
+		log.Printf("Error retrieving chirp: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	ancestors, err := cfg.db.GetChirpAncestors(r.Context(), chirpId)
+	if err != nil {
+		log.Printf("Error retrieving chirp ancestors: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	descendants, err := cfg.db.GetChirpDescendants(r.Context(), database.GetChirpDescendantsParams{
+		ID:             chirpId,
+		AfterCreatedAt: page.afterCreatedAt(),
+		AfterID:        page.afterID(),
+		Limit:          page.fetchLimit(),
+	})
+	if err != nil {
+		log.Printf("Error retrieving chirp replies: %s", err)
+		respondWithError(w, 500, "Something went wrong")
+		return
+	}
+
+	thread := chirpThread{
+		Ancestors: make([]chirpResponse, 0, len(ancestors)),
+		Chirp:     cfg.newChirpResponseWithAttachments(r.Context(), chirp),
+	}
+	for _, ancestor := range ancestors {
+		thread.Ancestors = append(thread.Ancestors, newChirpResponse(database.Chirp(ancestor)))
+	}
+	replies := make([]database.Chirp, 0, len(descendants))
+	for _, descendant := range descendants {
+		replies = append(replies, database.Chirp(descendant))
+	}
+	repliesPage := newChirpsPage(replies, page.Limit)
+	thread.Replies = repliesPage.Chirps
+	thread.NextCursor = repliesPage.NextCursor
+	cfg.loadAttachments(r.Context(), thread.Ancestors)
+	cfg.loadAttachments(r.Context(), thread.Replies)
+
+	respondWithJSON(w, 200, thread)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"internal/database"
+	"os"
+)
+
+const (
+	tierFree = "free"
+	tierRed  = "red"
+)
+
+// entitlements are what a tier allows its users to do. They are looked up
+// per request rather than checked against is_chirpy_red directly, so perks
+// can be retuned from configuration.
+type entitlements struct {
+	MaxChirpLength int  `json:"max_chirp_length"`
+	CanEditChirps  bool `json:"can_edit_chirps"`
+	// EditWindowSeconds is how long after posting a chirp can still be
+	// edited; zero means indefinitely.
+	EditWindowSeconds int `json:"edit_window_seconds"`
+	// ChirpsPerHour caps chirps posted or scheduled in any rolling hour;
+	// zero means unlimited.
+	ChirpsPerHour     int  `json:"chirps_per_hour"`
+	CanScheduleChirps bool `json:"can_schedule_chirps"`
+}
+
+type tierConfig map[string]entitlements
+
+var defaultTiers = tierConfig{
+	tierFree: {
+		MaxChirpLength: 140,
+		ChirpsPerHour:  30,
+	},
+	tierRed: {
+		MaxChirpLength:    500,
+		CanEditChirps:     true,
+		EditWindowSeconds: 30 * 60,
+		ChirpsPerHour:     300,
+		CanScheduleChirps: true,
+	},
+}
+
+// loadTiers reads the tier entitlements from a JSON file mapping tier names
+// to entitlements. Tiers missing from the file keep their defaults.
+func loadTiers(path string) (tierConfig, error) {
+	tiers := tierConfig{}
+	for name, perks := range defaultTiers {
+		tiers[name] = perks
+	}
+	if path == "" {
+		return tiers, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	configured := tierConfig{}
+	if err := json.Unmarshal(data, &configured); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for name, perks := range configured {
+		if _, ok := defaultTiers[name]; !ok {
+			return nil, fmt.Errorf("unknown tier %q in %s", name, path)
+		}
+		if perks.MaxChirpLength <= 0 || perks.ChirpsPerHour < 0 || perks.EditWindowSeconds < 0 {
+			return nil, fmt.Errorf("tier %q in %s: max_chirp_length must be positive, chirps_per_hour and edit_window_seconds not negative", name, path)
+		}
+		tiers[name] = perks
+	}
+	return tiers, nil
+}
+
+// forUser returns the entitlements of the tier user currently belongs to.
+func (tiers tierConfig) forUser(user database.User) entitlements {
+	if user.IsChirpyRed {
+		return tiers[tierRed]
+	}
+	return tiers[tierFree]
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"internal/auth"
+	"internal/chirptext"
+	"internal/stream"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsAuthTimeout    = 10 * time.Second
+	wsWriteTimeout   = 10 * time.Second
+	wsPingInterval   = 30 * time.Second
+	wsReadTimeout    = 2 * wsPingInterval
+	wsExpiryWarning  = time.Minute
+	wsMaxMessageSize = 4096
+	wsMaxTopics      = 100
+
+	// wsCloseUnauthorized closes connections that never authenticate or
+	// whose token expires without being replaced.
+	wsCloseUnauthorized = 4001
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// wsClientMessage is a message from the client:
+//
+//	{"type": "auth", "token": "..."}         authenticate, or replace an expiring token
+//	{"type": "subscribe", "topic": "..."}    start receiving a topic's events
+//	{"type": "unsubscribe", "topic": "..."}
+//	{"type": "ping"}
+//
+// Topics are "timeline", "notifications", "messages", "user:<user id>" and
+// "tag:<tag>".
+type wsClientMessage struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+	Topic string `json:"topic"`
+}
+
+type wsServerMessage struct {
+	Type string `json:"type"`
+	// Topic is the topic a subscribe or unsubscribe applied to.
+	Topic string `json:"topic,omitempty"`
+	// Topics are the subscribed topics an event was delivered for.
+	Topics    []string        `json:"topics,omitempty"`
+	Event     string          `json:"event,omitempty"`
+	ID        uint64          `json:"id,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// wsConn is one authenticated WebSocket connection. A reader goroutine
+// handles client messages while the handler's goroutine does all writing,
+// as gorilla/websocket allows only one writer.
+type wsConn struct {
+	cfg    *apiConfig
+	conn   *websocket.Conn
+	userID uuid.UUID
+	// topics maps each subscribed topic to the broker topics it covers.
+	// Only the reader goroutine uses it.
+	topics map[string][]string
+	// routes is the inverse of topics. The broker reads it when matching
+	// events, so it is replaced whole rather than modified.
+	routes   atomic.Pointer[map[string][]string]
+	replies  chan wsServerMessage
+	expiries chan time.Time
+	done     chan struct{}
+}
+
+// handlerWebSocket serves the WebSocket API. Clients that can set headers
+// authenticate with the usual bearer token; others must send an auth
+// message first. Before the token expires the client is asked for a new
+// one, and the connection is closed if none arrives in time.
+func (cfg *apiConfig) handlerWebSocket(w http.ResponseWriter, r *http.Request) {
+	var userId uuid.UUID
+	var expiresAt time.Time
+	authenticated := false
+	if token, err := auth.GetBearerToken(r.Header); err == nil {
+		userId, expiresAt, err = auth.ValidateJWTWithExpiry(token, cfg.jwtKeys)
+		if err != nil {
+			log.Printf("Invalid token: %s", err)
+			respondWithError(w, 401, "Unauthorized")
+			return
+		}
+		authenticated = true
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already responded.
+		log.Printf("Error upgrading to WebSocket: %s", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(wsMaxMessageSize)
+
+	if !authenticated {
+		conn.SetReadDeadline(time.Now().Add(wsAuthTimeout))
+		var msg wsClientMessage
+		err := conn.ReadJSON(&msg)
+		if err != nil || msg.Type != "auth" {
+			wsClose(conn, wsCloseUnauthorized, "Authenticate first")
+			return
+		}
+		userId, expiresAt, err = auth.ValidateJWTWithExpiry(msg.Token, cfg.jwtKeys)
+		if err != nil {
+			log.Printf("Invalid token: %s", err)
+			wsClose(conn, wsCloseUnauthorized, "Unauthorized")
+			return
+		}
+	}
+
+	c := &wsConn{
+		cfg:      cfg,
+		conn:     conn,
+		userID:   userId,
+		topics:   make(map[string][]string),
+		replies:  make(chan wsServerMessage, 16),
+		expiries: make(chan time.Time),
+		done:     make(chan struct{}),
+	}
+	c.routes.Store(&map[string][]string{})
+
+	// The subscription's bounded buffer is the backpressure: a client that
+	// cannot keep up is disconnected rather than queued for without limit.
+	sub := cfg.events.Subscribe(0, c.match)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- c.readLoop(ctx)
+	}()
+
+	err = c.write(wsServerMessage{Type: "authenticated", ExpiresAt: &expiresAt})
+	if err != nil {
+		return
+	}
+	c.writeLoop(sub, expiresAt, readErr)
+}
+
+func (c *wsConn) readLoop(ctx context.Context) error {
+	c.conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		c.conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+
+		var msg wsClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.reply(wsServerMessage{Type: "error", Error: "Messages must be JSON objects"})
+			continue
+		}
+
+		switch msg.Type {
+		case "auth":
+			userId, expiresAt, err := auth.ValidateJWTWithExpiry(msg.Token, c.cfg.jwtKeys)
+			if err != nil || userId != c.userID {
+				c.reply(wsServerMessage{Type: "error", Error: "Invalid token"})
+				continue
+			}
+			select {
+			case c.expiries <- expiresAt:
+			case <-c.done:
+				return nil
+			}
+			c.reply(wsServerMessage{Type: "authenticated", ExpiresAt: &expiresAt})
+		case "subscribe":
+			topic, err := c.subscribe(ctx, msg.Topic)
+			if err != nil {
+				c.reply(wsServerMessage{Type: "error", Topic: msg.Topic, Error: err.Error()})
+				continue
+			}
+			c.reply(wsServerMessage{Type: "subscribed", Topic: topic})
+		case "unsubscribe":
+			delete(c.topics, msg.Topic)
+			c.updateRoutes()
+			c.reply(wsServerMessage{Type: "unsubscribed", Topic: msg.Topic})
+		case "ping":
+			c.reply(wsServerMessage{Type: "pong"})
+		default:
+			c.reply(wsServerMessage{Type: "error", Error: "Unknown message type"})
+		}
+	}
+}
+
+func (c *wsConn) writeLoop(sub *stream.Subscription, expiresAt time.Time, readErr <-chan error) {
+	defer close(c.done)
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+	warning := time.NewTimer(time.Until(expiresAt.Add(-wsExpiryWarning)))
+	defer warning.Stop()
+	expiry := time.NewTimer(time.Until(expiresAt))
+	defer expiry.Stop()
+
+	for {
+		var err error
+		select {
+		case <-readErr:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				wsClose(c.conn, websocket.CloseTryAgainLater, "Too far behind, reconnect and reload")
+				return
+			}
+			err = c.write(c.eventMessage(event))
+		case msg := <-c.replies:
+			err = c.write(msg)
+		case expiresAt = <-c.expiries:
+			warning.Reset(time.Until(expiresAt.Add(-wsExpiryWarning)))
+			expiry.Reset(time.Until(expiresAt))
+		case <-warning.C:
+			err = c.write(wsServerMessage{Type: "token_expiring", ExpiresAt: &expiresAt})
+		case <-expiry.C:
+			wsClose(c.conn, wsCloseUnauthorized, "Token expired")
+			return
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// subscribe resolves a client topic to the broker topics it covers and
+// returns the topic's canonical name.
+func (c *wsConn) subscribe(ctx context.Context, topic string) (string, error) {
+	if len(c.topics) >= wsMaxTopics {
+		return "", errors.New("Too many subscriptions")
+	}
+
+	var brokerTopics []string
+	switch {
+	case topic == "timeline":
+		timeline, err := c.cfg.timelineTopics(ctx, c.userID)
+		if err != nil {
+			log.Printf("Error retrieving followed users: %s", err)
+			return "", errors.New("Something went wrong")
+		}
+		for brokerTopic := range timeline {
+			brokerTopics = append(brokerTopics, brokerTopic)
+		}
+	case topic == "notifications":
+		brokerTopics = []string{notificationTopic(c.userID)}
+	case topic == "messages":
+		brokerTopics = []string{messageTopic(c.userID)}
+	case strings.HasPrefix(topic, "user:"):
+		userId, err := uuid.Parse(strings.TrimPrefix(topic, "user:"))
+		if err != nil {
+			return "", errors.New("Invalid user ID")
+		}
+		topic = userTopic(userId)
+		brokerTopics = []string{topic}
+	case strings.HasPrefix(topic, "tag:"):
+		tag := strings.TrimPrefix(strings.TrimPrefix(topic, "tag:"), "#")
+		tag = strings.ToLower(chirptext.Normalize(tag))
+		if tag == "" {
+			return "", errors.New("Invalid tag")
+		}
+		topic = tagTopic(tag)
+		brokerTopics = []string{topic}
+	default:
+		return "", errors.New("Unknown topic")
+	}
+
+	c.topics[topic] = brokerTopics
+	c.updateRoutes()
+	return topic, nil
+}
+
+func (c *wsConn) updateRoutes() {
+	routes := make(map[string][]string)
+	for topic, brokerTopics := range c.topics {
+		for _, brokerTopic := range brokerTopics {
+			routes[brokerTopic] = append(routes[brokerTopic], topic)
+		}
+	}
+	c.routes.Store(&routes)
+}
+
+// match is called by the broker for every published event.
+func (c *wsConn) match(event stream.Event) bool {
+	routes := *c.routes.Load()
+	for _, topic := range event.Topics {
+		if len(routes[topic]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *wsConn) eventMessage(event stream.Event) wsServerMessage {
+	routes := *c.routes.Load()
+	seen := make(map[string]bool)
+	var topics []string
+	for _, brokerTopic := range event.Topics {
+		for _, topic := range routes[brokerTopic] {
+			if !seen[topic] {
+				seen[topic] = true
+				topics = append(topics, topic)
+			}
+		}
+	}
+	sort.Strings(topics)
+	return wsServerMessage{
+		Type:   "event",
+		Topics: topics,
+		Event:  event.Type,
+		ID:     event.ID,
+		Data:   event.Data,
+	}
+}
+
+// reply queues msg for the writer, unless the connection is closing.
+func (c *wsConn) reply(msg wsServerMessage) {
+	select {
+	case c.replies <- msg:
+	case <-c.done:
+	}
+}
+
+// write must only be called from the writer goroutine. The deadline stops a
+// client that has stopped reading from holding the connection open.
+func (c *wsConn) write(msg wsServerMessage) error {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteJSON(msg)
+}
+
+func wsClose(conn *websocket.Conn, code int, text string) {
+	message := websocket.FormatCloseMessage(code, text)
+	conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteTimeout))
+}